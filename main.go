@@ -3,17 +3,64 @@ package main
 import (
 	"api/src/config"
 	"api/src/router"
+	"api/src/tarefas"
+	"context"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
+// prazoEncerramento é quanto tempo as requisições em andamento têm para terminar quando a API é encerrada
+const prazoEncerramento = 10 * time.Second
+
 func main() {
+	reconstruirIndice := flag.Bool("reconstruir-indice", false,
+		"reconstrói o índice de busca a partir do banco, mostra quantos registros foram indexados e sai. "+
+			"Para reconstruir o índice de uma API em execução, envie SIGHUP ao processo")
+	flag.Parse()
+
 	config.Carregar()
 
+	if *reconstruirIndice {
+		if erro := tarefas.ReconstruirIndice(); erro != nil {
+			log.Fatalf("erro ao reconstruir o índice de busca: %v", erro)
+		}
+		return
+	}
+
 	fmt.Printf("Rodando a API NA PORTA: %d", config.Porta)
 
-	r := router.Gerar()
+	tarefas.IniciarIndice()
+	tarefas.IniciarExpurgo()
+	tarefas.IniciarAgendador()
+	tarefas.IniciarTendencias()
+	tarefas.IniciarGravacaoDeVisualizacoes()
+
+	servidor := &http.Server{Addr: fmt.Sprintf(":%d", config.Porta), Handler: router.Gerar()}
+
+	go func() {
+		if erro := servidor.ListenAndServe(); erro != http.ErrServerClosed {
+			log.Fatal(erro)
+		}
+	}()
+
+	encerrar := make(chan os.Signal, 1)
+	signal.Notify(encerrar, os.Interrupt, syscall.SIGTERM)
+	<-encerrar
+
+	ctx, cancelar := context.WithTimeout(context.Background(), prazoEncerramento)
+	defer cancelar()
+
+	if erro := servidor.Shutdown(ctx); erro != nil {
+		log.Printf("erro ao encerrar o servidor: %v", erro)
+	}
 
-	log.Fatal(http.ListenAndServe(fmt.Sprintf(":%d", config.Porta), r))
+	if erro := tarefas.GravarVisualizacoes(); erro != nil {
+		log.Printf("erro ao gravar visualizações: %v", erro)
+	}
 }
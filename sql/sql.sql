@@ -1,7 +1,42 @@
 CREATE DATABASE IF NOT EXISTS devbook;
 USE devbook;
 
+DROP TABLE IF EXISTS estatisticas_publicacoes;
+DROP TABLE IF EXISTS tendencias_tags;
+DROP TABLE IF EXISTS tendencias_publicacoes;
+DROP TABLE IF EXISTS historico_estados;
+DROP TABLE IF EXISTS listas_seguidas;
+DROP TABLE IF EXISTS lista_membros;
+DROP TABLE IF EXISTS listas;
+DROP TABLE IF EXISTS decisoes_moderacao;
+DROP TABLE IF EXISTS denuncias;
+DROP TABLE IF EXISTS mensagens;
+DROP TABLE IF EXISTS conversa_participantes;
+DROP TABLE IF EXISTS conversas;
+DROP TABLE IF EXISTS preferencias_notificacoes;
+DROP TABLE IF EXISTS notificacoes;
+DROP TABLE IF EXISTS mencoes;
+DROP TABLE IF EXISTS tags_seguidas;
+DROP TABLE IF EXISTS publicacao_tags;
+DROP TABLE IF EXISTS tags;
+DROP TABLE IF EXISTS enquete_escolhas;
+DROP TABLE IF EXISTS enquete_votos;
+DROP TABLE IF EXISTS enquete_opcoes;
+DROP TABLE IF EXISTS enquetes;
+DROP TABLE IF EXISTS revisoes_publicacoes;
+DROP TABLE IF EXISTS comentarios;
+DROP TABLE IF EXISTS curtidas;
+DROP TABLE IF EXISTS reacoes;
+DROP TABLE IF EXISTS repostagens;
+DROP TABLE IF EXISTS salvos;
+DROP TABLE IF EXISTS colecoes;
 DROP TABLE IF EXISTS publicacoes;
+DROP TABLE IF EXISTS comunidade_convites;
+DROP TABLE IF EXISTS comunidade_membros;
+DROP TABLE IF EXISTS comunidades;
+DROP TABLE IF EXISTS silenciamentos;
+DROP TABLE IF EXISTS bloqueios;
+DROP TABLE IF EXISTS solicitacoes_seguir;
 DROP TABLE IF EXISTS seguidores;
 DROP TABLE IF EXISTS usuarios;
 
@@ -11,7 +46,13 @@ CREATE TABLE usuarios(
     nick varchar(50) not null unique,
     email varchar(50) not null unique,
     senha varchar(200) not null,
-    criadoEm timestamp default current_timestamp()
+    criadoEm timestamp default current_timestamp(),
+    perfil enum('usuario', 'moderador', 'administrador') not null default 'usuario',
+    estado enum('ativo', 'suspenso', 'banido', 'desativado') not null default 'ativo',
+    suspenso_ate datetime,
+    deletadoEm datetime,
+    visibilidade_padrao enum('publico', 'seguidores', 'mencionados', 'privado') not null default 'publico',
+    conta_privada boolean not null default false
 )ENGINE=INNODB;
 
 CREATE TABLE seguidores(
@@ -23,10 +64,71 @@ CREATE TABLE seguidores(
     FOREIGN KEY (seguidor_id)
     REFERENCES usuarios(id)
     ON DELETE CASCADE,
+    criadoEm timestamp default current_timestamp(),
 
     primary key(usuario_id, seguidor_id)
 )ENGINE=INNODB;
 
+CREATE TABLE solicitacoes_seguir(
+    usuario_id int not null,
+    FOREIGN KEY (usuario_id)
+    REFERENCES usuarios(id)
+    ON DELETE CASCADE,
+    seguidor_id int not null,
+    FOREIGN KEY (seguidor_id)
+    REFERENCES usuarios(id)
+    ON DELETE CASCADE,
+    criadaEm timestamp default current_timestamp(),
+
+    primary key(usuario_id, seguidor_id)
+)ENGINE=INNODB;
+
+CREATE TABLE comunidades(
+    id int auto_increment primary key,
+    nome varchar(50) not null unique,
+    descricao varchar(500) not null default '',
+    privada boolean not null default false,
+    dono_id int not null,
+    FOREIGN KEY (dono_id)
+    REFERENCES usuarios(id)
+    ON DELETE CASCADE,
+    criadaEm timestamp default current_timestamp()
+)ENGINE=INNODB;
+
+CREATE TABLE comunidade_membros(
+    comunidade_id int not null,
+    FOREIGN KEY (comunidade_id)
+    REFERENCES comunidades(id)
+    ON DELETE CASCADE,
+    usuario_id int not null,
+    FOREIGN KEY (usuario_id)
+    REFERENCES usuarios(id)
+    ON DELETE CASCADE,
+    papel enum('membro', 'moderador', 'dono') not null default 'membro',
+    entrouEm timestamp default current_timestamp(),
+
+    primary key(comunidade_id, usuario_id),
+    INDEX (usuario_id)
+)ENGINE=INNODB;
+
+CREATE TABLE comunidade_convites(
+    comunidade_id int not null,
+    FOREIGN KEY (comunidade_id)
+    REFERENCES comunidades(id)
+    ON DELETE CASCADE,
+    usuario_id int not null,
+    FOREIGN KEY (usuario_id)
+    REFERENCES usuarios(id)
+    ON DELETE CASCADE,
+    convidado_por int not null,
+    FOREIGN KEY (convidado_por)
+    REFERENCES usuarios(id)
+    ON DELETE CASCADE,
+    criadoEm timestamp default current_timestamp(),
+
+    primary key(comunidade_id, usuario_id)
+)ENGINE=INNODB;
+
 CREATE TABLE publicacoes(
     id int auto_increment primary key,
     titulo varchar(100) not null,
@@ -36,5 +138,459 @@ CREATE TABLE publicacoes(
     REFERENCES usuarios(id)
     ON DELETE CASCADE,
     curtidas int default 0,
-    criadaEm timestamp default current_timestamp
-)ENGINE=INNODB;
\ No newline at end of file
+    criadaEm timestamp default current_timestamp,
+    comunidade_id int,
+    FOREIGN KEY (comunidade_id)
+    REFERENCES comunidades(id)
+    ON DELETE SET NULL,
+    oculta boolean not null default false,
+    deletadaEm datetime,
+    editadaEm datetime,
+    status enum('rascunho', 'agendada', 'publicada') not null default 'publicada',
+    publicarEm datetime,
+    visibilidade enum('publico', 'seguidores', 'mencionados', 'privado') not null default 'publico',
+    citacao_id int,
+    fixadaEm datetime,
+
+    FULLTEXT(titulo, conteudo)
+)ENGINE=INNODB;
+
+CREATE TABLE repostagens(
+    usuario_id int not null,
+    FOREIGN KEY (usuario_id)
+    REFERENCES usuarios(id)
+    ON DELETE CASCADE,
+    publicacao_id int not null,
+    FOREIGN KEY (publicacao_id)
+    REFERENCES publicacoes(id)
+    ON DELETE CASCADE,
+    criadaEm timestamp default current_timestamp(),
+
+    primary key(usuario_id, publicacao_id)
+)ENGINE=INNODB;
+
+CREATE TABLE colecoes(
+    id int auto_increment primary key,
+    usuario_id int not null,
+    FOREIGN KEY (usuario_id)
+    REFERENCES usuarios(id)
+    ON DELETE CASCADE,
+    nome varchar(50) not null,
+    criadaEm timestamp default current_timestamp(),
+
+    unique(usuario_id, nome)
+)ENGINE=INNODB;
+
+CREATE TABLE salvos(
+    usuario_id int not null,
+    FOREIGN KEY (usuario_id)
+    REFERENCES usuarios(id)
+    ON DELETE CASCADE,
+    publicacao_id int not null,
+    FOREIGN KEY (publicacao_id)
+    REFERENCES publicacoes(id)
+    ON DELETE CASCADE,
+    colecao_id int,
+    FOREIGN KEY (colecao_id)
+    REFERENCES colecoes(id)
+    ON DELETE SET NULL,
+    criadoEm timestamp default current_timestamp(),
+
+    primary key(usuario_id, publicacao_id)
+)ENGINE=INNODB;
+
+CREATE TABLE comentarios(
+    id int auto_increment primary key,
+    publicacao_id int not null,
+    FOREIGN KEY (publicacao_id)
+    REFERENCES publicacoes(id)
+    ON DELETE CASCADE,
+    autor_id int not null,
+    FOREIGN KEY (autor_id)
+    REFERENCES usuarios(id)
+    ON DELETE CASCADE,
+    conteudo varchar(500) not null,
+    criadoEm timestamp default current_timestamp(),
+
+    INDEX (publicacao_id, criadoEm)
+)ENGINE=INNODB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE curtidas(
+    usuario_id int not null,
+    FOREIGN KEY (usuario_id)
+    REFERENCES usuarios(id)
+    ON DELETE CASCADE,
+    publicacao_id int not null,
+    FOREIGN KEY (publicacao_id)
+    REFERENCES publicacoes(id)
+    ON DELETE CASCADE,
+    criadaEm timestamp default current_timestamp(),
+
+    primary key(usuario_id, publicacao_id),
+    INDEX (publicacao_id, criadaEm)
+)ENGINE=INNODB;
+
+CREATE TABLE reacoes(
+    usuario_id int not null,
+    FOREIGN KEY (usuario_id)
+    REFERENCES usuarios(id)
+    ON DELETE CASCADE,
+    publicacao_id int not null,
+    FOREIGN KEY (publicacao_id)
+    REFERENCES publicacoes(id)
+    ON DELETE CASCADE,
+    tipo varchar(16) not null,
+    criadaEm timestamp default current_timestamp(),
+
+    primary key(usuario_id, publicacao_id)
+)ENGINE=INNODB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE revisoes_publicacoes(
+    id int auto_increment primary key,
+    publicacao_id int not null,
+    FOREIGN KEY (publicacao_id)
+    REFERENCES publicacoes(id)
+    ON DELETE CASCADE,
+    titulo varchar(100) not null,
+    conteudo varchar(500) not null,
+    criadaEm datetime not null
+)ENGINE=INNODB;
+
+CREATE TABLE enquetes(
+    publicacao_id int not null primary key,
+    FOREIGN KEY (publicacao_id)
+    REFERENCES publicacoes(id)
+    ON DELETE CASCADE,
+    multipla boolean not null default false,
+    encerraEm datetime,
+    ocultar_resultados boolean not null default false
+)ENGINE=INNODB;
+
+CREATE TABLE enquete_opcoes(
+    id int auto_increment primary key,
+    publicacao_id int not null,
+    FOREIGN KEY (publicacao_id)
+    REFERENCES enquetes(publicacao_id)
+    ON DELETE CASCADE,
+    texto varchar(100) not null,
+    posicao int not null,
+
+    unique(publicacao_id, posicao)
+)ENGINE=INNODB;
+
+CREATE TABLE enquete_votos(
+    publicacao_id int not null,
+    FOREIGN KEY (publicacao_id)
+    REFERENCES enquetes(publicacao_id)
+    ON DELETE CASCADE,
+    usuario_id int not null,
+    FOREIGN KEY (usuario_id)
+    REFERENCES usuarios(id)
+    ON DELETE CASCADE,
+    criadoEm timestamp default current_timestamp(),
+
+    primary key(publicacao_id, usuario_id)
+)ENGINE=INNODB;
+
+CREATE TABLE enquete_escolhas(
+    publicacao_id int not null,
+    usuario_id int not null,
+    FOREIGN KEY (publicacao_id, usuario_id)
+    REFERENCES enquete_votos(publicacao_id, usuario_id)
+    ON DELETE CASCADE,
+    opcao_id int not null,
+    FOREIGN KEY (opcao_id)
+    REFERENCES enquete_opcoes(id)
+    ON DELETE CASCADE,
+
+    primary key(publicacao_id, usuario_id, opcao_id)
+)ENGINE=INNODB;
+
+CREATE TABLE tags(
+    id int auto_increment primary key,
+    nome varchar(50) not null unique
+)ENGINE=INNODB;
+
+CREATE TABLE publicacao_tags(
+    publicacao_id int not null,
+    FOREIGN KEY (publicacao_id)
+    REFERENCES publicacoes(id)
+    ON DELETE CASCADE,
+    tag_id int not null,
+    FOREIGN KEY (tag_id)
+    REFERENCES tags(id)
+    ON DELETE CASCADE,
+
+    primary key(publicacao_id, tag_id)
+)ENGINE=INNODB;
+
+CREATE TABLE tags_seguidas(
+    usuario_id int not null,
+    FOREIGN KEY (usuario_id)
+    REFERENCES usuarios(id)
+    ON DELETE CASCADE,
+    tag_id int not null,
+    FOREIGN KEY (tag_id)
+    REFERENCES tags(id)
+    ON DELETE CASCADE,
+
+    primary key(usuario_id, tag_id)
+)ENGINE=INNODB;
+
+CREATE TABLE bloqueios(
+    usuario_id int not null,
+    FOREIGN KEY (usuario_id)
+    REFERENCES usuarios(id)
+    ON DELETE CASCADE,
+    bloqueado_id int not null,
+    FOREIGN KEY (bloqueado_id)
+    REFERENCES usuarios(id)
+    ON DELETE CASCADE,
+    criadoEm timestamp default current_timestamp(),
+
+    primary key(usuario_id, bloqueado_id)
+)ENGINE=INNODB;
+
+CREATE TABLE silenciamentos(
+    usuario_id int not null,
+    FOREIGN KEY (usuario_id)
+    REFERENCES usuarios(id)
+    ON DELETE CASCADE,
+    silenciado_id int not null,
+    FOREIGN KEY (silenciado_id)
+    REFERENCES usuarios(id)
+    ON DELETE CASCADE,
+    criadoEm timestamp default current_timestamp(),
+
+    primary key(usuario_id, silenciado_id)
+)ENGINE=INNODB;
+
+CREATE TABLE mencoes(
+    publicacao_id int not null,
+    FOREIGN KEY (publicacao_id)
+    REFERENCES publicacoes(id)
+    ON DELETE CASCADE,
+    usuario_id int not null,
+    FOREIGN KEY (usuario_id)
+    REFERENCES usuarios(id)
+    ON DELETE CASCADE,
+    campo varchar(20) not null,
+    inicio int not null,
+    tamanho int not null,
+
+    primary key(publicacao_id, campo, inicio),
+    INDEX (usuario_id)
+)ENGINE=INNODB;
+
+CREATE TABLE notificacoes(
+    id int auto_increment primary key,
+    usuario_id int not null,
+    FOREIGN KEY (usuario_id)
+    REFERENCES usuarios(id)
+    ON DELETE CASCADE,
+    ator_id int not null,
+    FOREIGN KEY (ator_id)
+    REFERENCES usuarios(id)
+    ON DELETE CASCADE,
+    tipo varchar(20) not null,
+    publicacao_id int,
+    FOREIGN KEY (publicacao_id)
+    REFERENCES publicacoes(id)
+    ON DELETE CASCADE,
+    lida boolean not null default false,
+    criadaEm timestamp default current_timestamp(),
+
+    INDEX (usuario_id, lida)
+)ENGINE=INNODB;
+
+CREATE TABLE preferencias_notificacoes(
+    usuario_id int not null,
+    FOREIGN KEY (usuario_id)
+    REFERENCES usuarios(id)
+    ON DELETE CASCADE,
+    tipo varchar(20) not null,
+    ativa boolean not null default true,
+
+    primary key(usuario_id, tipo)
+)ENGINE=INNODB;
+
+CREATE TABLE conversas(
+    id int auto_increment primary key,
+    criador_id int,
+    FOREIGN KEY (criador_id)
+    REFERENCES usuarios(id)
+    ON DELETE SET NULL,
+    grupo boolean not null default false,
+    criadaEm timestamp default current_timestamp()
+)ENGINE=INNODB;
+
+CREATE TABLE conversa_participantes(
+    conversa_id int not null,
+    FOREIGN KEY (conversa_id)
+    REFERENCES conversas(id)
+    ON DELETE CASCADE,
+    usuario_id int not null,
+    FOREIGN KEY (usuario_id)
+    REFERENCES usuarios(id)
+    ON DELETE CASCADE,
+    ultima_leitura_id int not null default 0,
+    entrouEm timestamp default current_timestamp(),
+
+    primary key(conversa_id, usuario_id),
+    INDEX (usuario_id)
+)ENGINE=INNODB;
+
+CREATE TABLE mensagens(
+    id int auto_increment primary key,
+    conversa_id int not null,
+    FOREIGN KEY (conversa_id)
+    REFERENCES conversas(id)
+    ON DELETE CASCADE,
+    autor_id int not null,
+    FOREIGN KEY (autor_id)
+    REFERENCES usuarios(id)
+    ON DELETE CASCADE,
+    conteudo varchar(1000) not null,
+    criadaEm timestamp default current_timestamp(),
+
+    INDEX (conversa_id, id)
+)ENGINE=INNODB;
+
+CREATE TABLE denuncias(
+    id int auto_increment primary key,
+    denunciante_id int,
+    FOREIGN KEY (denunciante_id)
+    REFERENCES usuarios(id)
+    ON DELETE SET NULL,
+    usuario_id int,
+    FOREIGN KEY (usuario_id)
+    REFERENCES usuarios(id)
+    ON DELETE SET NULL,
+    publicacao_id int,
+    FOREIGN KEY (publicacao_id)
+    REFERENCES publicacoes(id)
+    ON DELETE SET NULL,
+    motivo enum('spam', 'assedio', 'discurso_de_odio', 'conteudo_improprio', 'outro') not null,
+    descricao varchar(500) not null default '',
+    status enum('aberta', 'em_analise', 'resolvida') not null default 'aberta',
+    moderador_id int,
+    FOREIGN KEY (moderador_id)
+    REFERENCES usuarios(id)
+    ON DELETE SET NULL,
+    criadaEm timestamp default current_timestamp(),
+    resolvidaEm datetime,
+
+    INDEX (status)
+)ENGINE=INNODB;
+
+CREATE TABLE decisoes_moderacao(
+    id int auto_increment primary key,
+    denuncia_id int not null,
+    FOREIGN KEY (denuncia_id)
+    REFERENCES denuncias(id),
+    moderador_id int not null,
+    acao enum('ocultar_publicacao', 'suspender_usuario', 'descartar') not null,
+    observacao varchar(500) not null default '',
+    dias_suspensao int not null default 0,
+    criadaEm timestamp default current_timestamp()
+)ENGINE=INNODB;
+
+CREATE TRIGGER decisoes_moderacao_sem_alteracao BEFORE UPDATE ON decisoes_moderacao
+FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'decisões de moderação não podem ser alteradas';
+
+CREATE TRIGGER decisoes_moderacao_sem_exclusao BEFORE DELETE ON decisoes_moderacao
+FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'decisões de moderação não podem ser apagadas';
+
+CREATE TABLE historico_estados(
+    id int auto_increment primary key,
+    usuario_id int not null,
+    FOREIGN KEY (usuario_id)
+    REFERENCES usuarios(id)
+    ON DELETE CASCADE,
+    estado enum('ativo', 'suspenso', 'banido', 'desativado') not null,
+    suspenso_ate datetime,
+    motivo varchar(500) not null,
+    alterado_por int,
+    FOREIGN KEY (alterado_por)
+    REFERENCES usuarios(id)
+    ON DELETE SET NULL,
+    criadoEm timestamp default current_timestamp(),
+
+    INDEX (usuario_id)
+)ENGINE=INNODB;
+
+CREATE TABLE listas(
+    id int auto_increment primary key,
+    nome varchar(50) not null,
+    descricao varchar(200) not null default '',
+    privada boolean not null default false,
+    dono_id int not null,
+    FOREIGN KEY (dono_id)
+    REFERENCES usuarios(id)
+    ON DELETE CASCADE,
+    criadaEm timestamp default current_timestamp()
+)ENGINE=INNODB;
+
+CREATE TABLE lista_membros(
+    lista_id int not null,
+    FOREIGN KEY (lista_id)
+    REFERENCES listas(id)
+    ON DELETE CASCADE,
+    usuario_id int not null,
+    FOREIGN KEY (usuario_id)
+    REFERENCES usuarios(id)
+    ON DELETE CASCADE,
+    criadoEm timestamp default current_timestamp(),
+
+    primary key(lista_id, usuario_id)
+)ENGINE=INNODB;
+
+CREATE TABLE listas_seguidas(
+    lista_id int not null,
+    FOREIGN KEY (lista_id)
+    REFERENCES listas(id)
+    ON DELETE CASCADE,
+    usuario_id int not null,
+    FOREIGN KEY (usuario_id)
+    REFERENCES usuarios(id)
+    ON DELETE CASCADE,
+    criadoEm timestamp default current_timestamp(),
+
+    primary key(lista_id, usuario_id)
+)ENGINE=INNODB;
+
+CREATE TABLE tendencias_publicacoes(
+    janela enum('1h', '24h', '7d') not null,
+    publicacao_id int not null,
+    FOREIGN KEY (publicacao_id)
+    REFERENCES publicacoes(id)
+    ON DELETE CASCADE,
+    pontuacao double not null,
+
+    primary key(janela, publicacao_id)
+)ENGINE=INNODB;
+
+CREATE TABLE tendencias_tags(
+    janela enum('1h', '24h', '7d') not null,
+    tag_id int not null,
+    FOREIGN KEY (tag_id)
+    REFERENCES tags(id)
+    ON DELETE CASCADE,
+    pontuacao double not null,
+    publicacoes int not null,
+
+    primary key(janela, tag_id)
+)ENGINE=INNODB;
+
+CREATE TABLE estatisticas_publicacoes(
+    publicacao_id int not null,
+    FOREIGN KEY (publicacao_id)
+    REFERENCES publicacoes(id)
+    ON DELETE CASCADE,
+    dia date not null,
+    impressoes int not null default 0,
+    visualizacoes int not null default 0,
+    curtidas int not null default 0,
+
+    primary key(publicacao_id, dia)
+)ENGINE=INNODB;
@@ -2,6 +2,7 @@ package autenticacao
 
 import (
 	"api/src/config"
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -24,50 +25,88 @@ func CriarToken(usuarioID uint64) (string, error) {
 	return token.SignedString(config.SecretKey)
 }
 
-// ValidarToken verifica se um token passado na requisição é valido
-func ValidarToken(r *http.Request) error {
-	tokenString := extrairToken(r)
-	token, erro := jwt.Parse(tokenString, retornarChaveVerificacao)
-	if erro != nil {
-		return erro
-	}
+// EscopoEventos é o escopo dos tokens usados para abrir a conexão de eventos em tempo real
+const EscopoEventos = "eventos"
 
-	if _, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		return nil
-	}
+type chaveEscopo struct{}
+
+// CriarTokenDeEscopo gera um token de curta duração que só vale nas rotas do escopo. Ele serve
+// para clientes que não conseguem enviar o cabeçalho Authorization, como EventSource e WebSocket
+// no navegador, e pode ser passado no parâmetro token da URL
+func CriarTokenDeEscopo(usuarioID uint64, escopo string, validade time.Duration) (string, error) {
+	permissoes := jwt.MapClaims{}
+	permissoes["autorized"] = true
+	permissoes["exp"] = time.Now().Add(validade).Unix()
+	permissoes["usuarioId"] = usuarioID
+	permissoes["escopo"] = escopo
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, permissoes)
+
+	return token.SignedString(config.SecretKey)
+}
 
-	return errors.New("Token inválido")
+// PermitirEscopo marca a requisição como de uma rota que aceita tokens do escopo informado
+func PermitirEscopo(r *http.Request, escopo string) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), chaveEscopo{}, escopo))
+}
 
+// ValidarToken verifica se um token passado na requisição é valido
+func ValidarToken(r *http.Request) error {
+	_, erro := validarPermissoes(r)
+	return erro
 }
 
 // ExtrairUsuarioID extrai o usuarioid que esta no token
 func ExtrairUsuarioID(r *http.Request) (uint64, error) {
-	tokenString := extrairToken(r)
-	token, erro := jwt.Parse(tokenString, retornarChaveVerificacao)
+	permissoes, erro := validarPermissoes(r)
 	if erro != nil {
 		return 0, erro
 	}
 
-	if permissoes, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		usuarioID, erro := strconv.ParseUint(fmt.Sprintf("%.0f", permissoes["usuarioId"]), 10, 64)
-		if erro != nil {
-			return 0, erro
-		}
+	return strconv.ParseUint(fmt.Sprintf("%.0f", permissoes["usuarioId"]), 10, 64)
+}
 
-		return usuarioID, nil
+// validarPermissoes verifica o token da requisição e retorna suas permissões. Tokens de escopo
+// só são aceitos nas rotas marcadas com PermitirEscopo para o mesmo escopo, e só eles podem vir
+// na URL, para que tokens de longa duração não fiquem gravados em logs
+func validarPermissoes(r *http.Request) (jwt.MapClaims, error) {
+	tokenString, daURL := extrairToken(r)
+	token, erro := jwt.Parse(tokenString, retornarChaveVerificacao)
+	if erro != nil {
+		return nil, erro
 	}
 
-	return 0, errors.New("Token invalido")
+	permissoes, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, errors.New("Token inválido")
+	}
+
+	escopo, _ := permissoes["escopo"].(string)
+	if (escopo != "" || daURL) && escopo != escopoPermitido(r) {
+		return nil, errors.New("Token não vale para esta rota")
+	}
+
+	return permissoes, nil
 }
 
-func extrairToken(r *http.Request) string {
+// extrairToken retorna o token da requisição e se ele veio da URL
+func extrairToken(r *http.Request) (string, bool) {
 	token := r.Header.Get("Authorization")
 
 	if len(strings.Split(token, " ")) == 2 {
-		return strings.Split(token, " ")[1]
+		return strings.Split(token, " ")[1], false
 	}
 
-	return ""
+	if escopoPermitido(r) != "" {
+		return r.URL.Query().Get("token"), true
+	}
+
+	return "", false
+}
+
+func escopoPermitido(r *http.Request) string {
+	escopo, _ := r.Context().Value(chaveEscopo{}).(string)
+	return escopo
 }
 
 func retornarChaveVerificacao(token *jwt.Token) (interface{}, error) {
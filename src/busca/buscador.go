@@ -0,0 +1,63 @@
+package busca
+
+import "api/src/modelos"
+
+// Buscador mantém um índice de usuários e publicações para a busca por texto. Os repositórios
+// atualizam o índice ao criar, alterar e excluir registros; quem busca recebe apenas os IDs
+// encontrados e continua responsável por aplicar as regras de acesso ao carregá-los do banco
+type Buscador interface {
+	// IndexarUsuario inclui o usuário no índice ou atualiza seus dados
+	IndexarUsuario(usuario modelos.Usuario)
+	// RemoverUsuario tira o usuário do índice
+	RemoverUsuario(usuarioID uint64)
+	// IndexarPublicacao inclui a publicação no índice ou atualiza seu texto
+	IndexarPublicacao(publicacao modelos.Publicacao)
+	// RemoverPublicacao tira a publicação do índice
+	RemoverPublicacao(publicacaoID uint64)
+	// BuscarUsuarios retorna os IDs dos usuários cujo nome ou nick contém todos os termos,
+	// aceitando o início do nick, dos mais relevantes para os menos
+	BuscarUsuarios(consulta string, limite int) []uint64
+	// BuscarPublicacoes retorna as publicações que contêm todos os termos, das mais relevantes
+	// para as menos, pulando as primeiras deslocamento
+	BuscarPublicacoes(consulta string, limite, deslocamento int) []Resultado
+	// Limpar esvazia o índice para uma reconstrução. Até Concluir ser chamado, ele está incompleto
+	Limpar()
+	// Concluir indica que a reconstrução terminou e o índice tem todos os registros
+	Concluir()
+}
+
+// Resultado é um documento encontrado e sua relevância para a consulta
+type Resultado struct {
+	ID         uint64
+	Relevancia float64
+}
+
+// IndiceComReserva usa o índice em memória quando ele está completo e consulta a reserva enquanto
+// ele ainda não foi carregado ou está sendo reconstruído. As alterações vão sempre para o índice,
+// para que ele não perca nada durante a reconstrução
+type IndiceComReserva struct {
+	*Indice
+	Reserva Buscador
+}
+
+// BuscarUsuarios busca no índice, ou na reserva se o índice estiver incompleto
+func (buscador *IndiceComReserva) BuscarUsuarios(consulta string, limite int) []uint64 {
+	if !buscador.Completo() {
+		return buscador.Reserva.BuscarUsuarios(consulta, limite)
+	}
+
+	return buscador.Indice.BuscarUsuarios(consulta, limite)
+}
+
+// BuscarPublicacoes busca no índice, ou na reserva se o índice estiver incompleto
+func (buscador *IndiceComReserva) BuscarPublicacoes(consulta string, limite, deslocamento int) []Resultado {
+	if !buscador.Completo() {
+		return buscador.Reserva.BuscarPublicacoes(consulta, limite, deslocamento)
+	}
+
+	return buscador.Indice.BuscarPublicacoes(consulta, limite, deslocamento)
+}
+
+// Padrao é o buscador usado pela API: o índice em memória, com o banco como reserva até o
+// índice ser carregado
+var Padrao Buscador = &IndiceComReserva{Indice: NovoIndice(), Reserva: BuscadorSQL{}}
@@ -0,0 +1,367 @@
+package busca
+
+import (
+	"api/src/modelos"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Indice é um Buscador em memória baseado em índice invertido. Os termos são comparados
+// sem diferenciar maiúsculas nem acentos
+type Indice struct {
+	mutex       sync.RWMutex
+	usuarios    *termos
+	publicacoes *termos
+
+	// nicks guarda os nicks normalizados em ordem, para a busca pelo início do nick
+	nicks     []nickIndexado
+	nickPorID map[uint64]string
+
+	// completo indica que a última reconstrução terminou
+	completo bool
+}
+
+type nickIndexado struct {
+	nick string
+	id   uint64
+}
+
+// termos relaciona cada termo aos documentos que o contêm e quantas vezes, já multiplicadas
+// pelo peso do campo em que aparecem. Guarda também o tamanho ponderado de cada documento,
+// usado para que textos longos não ganhem relevância só por repetirem os termos
+type termos struct {
+	documentos   map[string]map[uint64]int
+	termosDe     map[uint64][]string
+	tamanhos     map[uint64]int
+	tamanhoTotal int
+}
+
+// campo é um trecho de um documento e o peso das ocorrências de termos nele
+type campo struct {
+	texto string
+	peso  int
+}
+
+// pesoTitulo é quanto vale um termo no título de uma publicação em relação ao conteúdo
+const pesoTitulo = 2
+
+// Parâmetros do BM25: k1 limita quanto a repetição de um termo aumenta a relevância e b
+// quanto o tamanho do documento a reduz
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// NovoIndice cria um índice vazio
+func NovoIndice() *Indice {
+	indice := &Indice{}
+	indice.Limpar()
+	return indice
+}
+
+// Limpar esvazia o índice
+func (indice *Indice) Limpar() {
+	indice.mutex.Lock()
+	defer indice.mutex.Unlock()
+
+	indice.usuarios = novosTermos()
+	indice.publicacoes = novosTermos()
+	indice.nicks = nil
+	indice.nickPorID = make(map[uint64]string)
+	indice.completo = false
+}
+
+// Concluir indica que a reconstrução terminou e o índice tem todos os registros
+func (indice *Indice) Concluir() {
+	indice.mutex.Lock()
+	defer indice.mutex.Unlock()
+
+	indice.completo = true
+}
+
+// Completo indica se o índice já foi carregado por completo desde a última limpeza
+func (indice *Indice) Completo() bool {
+	indice.mutex.RLock()
+	defer indice.mutex.RUnlock()
+
+	return indice.completo
+}
+
+// IndexarUsuario inclui o usuário no índice ou atualiza seus dados
+func (indice *Indice) IndexarUsuario(usuario modelos.Usuario) {
+	indice.mutex.Lock()
+	defer indice.mutex.Unlock()
+
+	indice.removerNick(usuario.ID)
+	indice.usuarios.indexar(usuario.ID, campo{usuario.Nome + " " + usuario.Nick, 1})
+
+	nick := NormalizarNick(usuario.Nick)
+	posicao := sort.Search(len(indice.nicks), func(i int) bool { return indice.nicks[i].nick >= nick })
+	indice.nicks = append(indice.nicks, nickIndexado{})
+	copy(indice.nicks[posicao+1:], indice.nicks[posicao:])
+	indice.nicks[posicao] = nickIndexado{nick, usuario.ID}
+	indice.nickPorID[usuario.ID] = nick
+}
+
+// RemoverUsuario tira o usuário do índice
+func (indice *Indice) RemoverUsuario(usuarioID uint64) {
+	indice.mutex.Lock()
+	defer indice.mutex.Unlock()
+
+	indice.removerNick(usuarioID)
+	indice.usuarios.remover(usuarioID)
+}
+
+// IndexarPublicacao inclui a publicação no índice ou atualiza seu texto
+func (indice *Indice) IndexarPublicacao(publicacao modelos.Publicacao) {
+	indice.mutex.Lock()
+	defer indice.mutex.Unlock()
+
+	indice.publicacoes.indexar(publicacao.ID, campo{publicacao.Titulo, pesoTitulo}, campo{publicacao.Conteudo, 1})
+}
+
+// RemoverPublicacao tira a publicação do índice
+func (indice *Indice) RemoverPublicacao(publicacaoID uint64) {
+	indice.mutex.Lock()
+	defer indice.mutex.Unlock()
+
+	indice.publicacoes.remover(publicacaoID)
+}
+
+// BuscarUsuarios retorna os usuários cujo nome ou nick tem todos os termos da consulta. Cada termo
+// também é aceito como início do nick. Aparecem primeiro o nick exato, depois os que começam pela
+// consulta e por fim os demais, com os mais antigos antes
+func (indice *Indice) BuscarUsuarios(consulta string, limite int) []uint64 {
+	indice.mutex.RLock()
+	defer indice.mutex.RUnlock()
+
+	palavras := modelos.ExtrairTermos(consulta)
+	if len(palavras) == 0 {
+		return nil
+	}
+
+	var encontrados map[uint64]int
+	for _, palavra := range palavras {
+		candidatos := make(map[uint64]int)
+		for id := range indice.usuarios.documentos[palavra] {
+			candidatos[id] = 0
+		}
+		for _, id := range indice.comNickIniciadoPor(palavra) {
+			candidatos[id] = 0
+		}
+
+		encontrados = intersecao(encontrados, candidatos)
+	}
+
+	nickBuscado := NormalizarNick(consulta)
+	for id := range encontrados {
+		switch nick := indice.nickPorID[id]; {
+		case nick == nickBuscado:
+			encontrados[id] = 2
+		case strings.HasPrefix(nick, nickBuscado):
+			encontrados[id] = 1
+		}
+	}
+
+	return ordenar(encontrados, limite, true)
+}
+
+// BuscarPublicacoes retorna as publicações que têm todos os termos da consulta, das mais relevantes
+// para as menos e, no empate, das mais recentes para as mais antigas, pulando as primeiras
+// deslocamento. A relevância é calculada pelo BM25, com os termos do título valendo mais que os
+// do conteúdo
+func (indice *Indice) BuscarPublicacoes(consulta string, limite, deslocamento int) []Resultado {
+	indice.mutex.RLock()
+	defer indice.mutex.RUnlock()
+
+	palavras := modelos.ExtrairTermos(consulta)
+	if len(palavras) == 0 {
+		return nil
+	}
+
+	var encontradas map[uint64]int
+	for _, palavra := range palavras {
+		candidatas := make(map[uint64]int, len(indice.publicacoes.documentos[palavra]))
+		for id := range indice.publicacoes.documentos[palavra] {
+			candidatas[id] = 0
+		}
+
+		encontradas = intersecao(encontradas, candidatas)
+	}
+
+	relevancias := make(map[uint64]float64, len(encontradas))
+	for id := range encontradas {
+		for _, palavra := range palavras {
+			relevancias[id] += indice.publicacoes.bm25(palavra, id)
+		}
+	}
+
+	ids := make([]uint64, 0, len(relevancias))
+	for id := range relevancias {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		if relevancias[ids[i]] != relevancias[ids[j]] {
+			return relevancias[ids[i]] > relevancias[ids[j]]
+		}
+		return ids[i] > ids[j]
+	})
+
+	if deslocamento >= len(ids) {
+		return nil
+	}
+
+	ids = ids[deslocamento:]
+	if limite > 0 && len(ids) > limite {
+		ids = ids[:limite]
+	}
+
+	resultados := make([]Resultado, 0, len(ids))
+	for _, id := range ids {
+		resultados = append(resultados, Resultado{ID: id, Relevancia: relevancias[id]})
+	}
+
+	return resultados
+}
+
+// NormalizarNick deixa um nick (ou consulta por nick) no formato usado pelo índice
+func NormalizarNick(nick string) string {
+	return modelos.NormalizarTexto(strings.TrimPrefix(strings.TrimSpace(nick), "@"))
+}
+
+func (indice *Indice) comNickIniciadoPor(prefixo string) []uint64 {
+	var ids []uint64
+
+	inicio := sort.Search(len(indice.nicks), func(i int) bool { return indice.nicks[i].nick >= prefixo })
+	for i := inicio; i < len(indice.nicks) && strings.HasPrefix(indice.nicks[i].nick, prefixo); i++ {
+		ids = append(ids, indice.nicks[i].id)
+	}
+
+	return ids
+}
+
+func (indice *Indice) removerNick(usuarioID uint64) {
+	nick, existe := indice.nickPorID[usuarioID]
+	if !existe {
+		return
+	}
+
+	inicio := sort.Search(len(indice.nicks), func(i int) bool { return indice.nicks[i].nick >= nick })
+	for i := inicio; i < len(indice.nicks) && indice.nicks[i].nick == nick; i++ {
+		if indice.nicks[i].id == usuarioID {
+			indice.nicks = append(indice.nicks[:i], indice.nicks[i+1:]...)
+			break
+		}
+	}
+
+	delete(indice.nickPorID, usuarioID)
+}
+
+func novosTermos() *termos {
+	return &termos{
+		documentos: make(map[string]map[uint64]int),
+		termosDe:   make(map[uint64][]string),
+		tamanhos:   make(map[uint64]int),
+	}
+}
+
+func (t *termos) indexar(id uint64, campos ...campo) {
+	t.remover(id)
+
+	ocorrencias := make(map[string]int)
+	tamanho := 0
+	for _, c := range campos {
+		for _, termo := range strings.FieldsFunc(modelos.NormalizarTexto(c.texto), modelos.SeparaTermos) {
+			ocorrencias[termo] += c.peso
+			tamanho += c.peso
+		}
+	}
+
+	for termo, quantidade := range ocorrencias {
+		if t.documentos[termo] == nil {
+			t.documentos[termo] = make(map[uint64]int)
+		}
+		t.documentos[termo][id] = quantidade
+		t.termosDe[id] = append(t.termosDe[id], termo)
+	}
+
+	t.tamanhos[id] = tamanho
+	t.tamanhoTotal += tamanho
+}
+
+func (t *termos) remover(id uint64) {
+	for _, termo := range t.termosDe[id] {
+		delete(t.documentos[termo], id)
+		if len(t.documentos[termo]) == 0 {
+			delete(t.documentos, termo)
+		}
+	}
+
+	t.tamanhoTotal -= t.tamanhos[id]
+	delete(t.tamanhos, id)
+	delete(t.termosDe, id)
+}
+
+// bm25 é a relevância do termo para o documento: cresce com as ocorrências, cada vez menos a cada
+// repetição, diminui em documentos maiores que a média e vale mais para termos raros
+func (t *termos) bm25(termo string, id uint64) float64 {
+	ocorrencias := float64(t.documentos[termo][id])
+	if ocorrencias == 0 {
+		return 0
+	}
+
+	documentos := float64(len(t.tamanhos))
+	comTermo := float64(len(t.documentos[termo]))
+	idf := math.Log(1 + (documentos-comTermo+0.5)/(comTermo+0.5))
+
+	tamanhoMedio := float64(t.tamanhoTotal) / documentos
+	normalizacao := 1 - bm25B + bm25B*float64(t.tamanhos[id])/tamanhoMedio
+
+	return idf * ocorrencias * (bm25K1 + 1) / (ocorrencias + bm25K1*normalizacao)
+}
+
+// intersecao mantém os documentos presentes nos dois conjuntos, somando suas pontuações.
+// Um conjunto atual nil representa o primeiro termo da consulta
+func intersecao(atual, candidatos map[uint64]int) map[uint64]int {
+	if atual == nil {
+		return candidatos
+	}
+
+	for id, pontos := range atual {
+		if extra, existe := candidatos[id]; existe {
+			atual[id] = pontos + extra
+		} else {
+			delete(atual, id)
+		}
+	}
+
+	return atual
+}
+
+// ordenar retorna os IDs pela maior pontuação. No empate, os IDs menores vêm antes se
+// crescente for verdadeiro, e os maiores caso contrário
+func ordenar(pontuacoes map[uint64]int, limite int, crescente bool) []uint64 {
+	ids := make([]uint64, 0, len(pontuacoes))
+	for id := range pontuacoes {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		if pontuacoes[ids[i]] != pontuacoes[ids[j]] {
+			return pontuacoes[ids[i]] > pontuacoes[ids[j]]
+		}
+		if crescente {
+			return ids[i] < ids[j]
+		}
+		return ids[i] > ids[j]
+	})
+
+	if limite > 0 && len(ids) > limite {
+		ids = ids[:limite]
+	}
+
+	return ids
+}
@@ -0,0 +1,212 @@
+package busca
+
+import (
+	"api/src/modelos"
+	"reflect"
+	"testing"
+)
+
+// iguais compara os resultados sem diferenciar um slice nil de um vazio
+func iguais(obtido, esperado interface{}) bool {
+	a, b := reflect.ValueOf(obtido), reflect.ValueOf(esperado)
+	if a.Len() == 0 && b.Len() == 0 {
+		return true
+	}
+
+	return reflect.DeepEqual(obtido, esperado)
+}
+
+func indiceDeTeste() *Indice {
+	indice := NovoIndice()
+
+	indice.IndexarUsuario(modelos.Usuario{ID: 1, Nome: "João Silva", Nick: "joaosilva"})
+	indice.IndexarUsuario(modelos.Usuario{ID: 2, Nome: "Maria Conceição", Nick: "mari"})
+	indice.IndexarUsuario(modelos.Usuario{ID: 3, Nome: "Mariana Souza", Nick: "mariana"})
+	indice.IndexarUsuario(modelos.Usuario{ID: 4, Nome: "José Mário", Nick: "ze.mario"})
+
+	indice.IndexarPublicacao(modelos.Publicacao{ID: 10, Titulo: "Café com pão", Conteudo: "café da manhã"})
+	indice.IndexarPublicacao(modelos.Publicacao{ID: 11, Titulo: "Pão de queijo", Conteudo: "receita de pão"})
+	indice.IndexarPublicacao(modelos.Publicacao{ID: 12, Titulo: "Cafe", Conteudo: "sem acento"})
+
+	return indice
+}
+
+func TestBuscarUsuarios(t *testing.T) {
+	testes := []struct {
+		nome     string
+		consulta string
+		esperado []uint64
+	}{
+		{"acento na consulta e no nome", "João", []uint64{1}},
+		{"consulta sem acento encontra nome com acento", "joao", []uint64{1}},
+		{"consulta com acento encontra nome sem acento", "Máriana", []uint64{3}},
+		{"maiúsculas e minúsculas", "CONCEIÇAO", []uint64{2}},
+		{"nick exato antes dos que começam pela consulta", "mari", []uint64{2, 3}},
+		{"início do nick", "maria", []uint64{3, 2}},
+		{"início do nick com arroba", "@joao", []uint64{1}},
+		{"nick com ponto", "ze.mario", []uint64{4}},
+		{"todos os termos precisam aparecer", "maria souza", []uint64{3}},
+		{"termo sem resultado", "pedro", nil},
+		{"consulta vazia", "  ", nil},
+	}
+
+	indice := indiceDeTeste()
+	for _, teste := range testes {
+		t.Run(teste.nome, func(t *testing.T) {
+			if obtido := indice.BuscarUsuarios(teste.consulta, 10); !iguais(obtido, teste.esperado) {
+				t.Errorf("BuscarUsuarios(%q) = %v, esperado %v", teste.consulta, obtido, teste.esperado)
+			}
+		})
+	}
+}
+
+func TestBuscarPublicacoes(t *testing.T) {
+	testes := []struct {
+		nome         string
+		consulta     string
+		limite       int
+		deslocamento int
+		esperado     []uint64
+	}{
+		{"mais ocorrências primeiro", "pão", 10, 0, []uint64{11, 10}},
+		{"sem acento encontra com acento", "cafe", 10, 0, []uint64{12, 10}},
+		{"todos os termos precisam aparecer", "pao queijo", 10, 0, []uint64{11}},
+		{"termo no título vale mais que no conteúdo", "manha", 10, 0, []uint64{13, 10}},
+		{"texto longo e repetitivo não vence um curto", "bolo", 10, 0, []uint64{15, 14}},
+		{"limite", "cafe", 1, 0, []uint64{12}},
+		{"deslocamento", "cafe", 10, 1, []uint64{10}},
+		{"deslocamento além dos resultados", "cafe", 10, 2, nil},
+		{"termo sem resultado", "chá", 10, 0, nil},
+	}
+
+	indice := indiceDeTeste()
+	indice.IndexarPublicacao(modelos.Publicacao{ID: 13, Titulo: "Manhã", Conteudo: "de sol"})
+	indice.IndexarPublicacao(modelos.Publicacao{ID: 14, Titulo: "Receita",
+		Conteudo: "bolo bolo bolo bolo de fubá, farinha, ovos, leite, açúcar, fermento, manteiga e um pouco de sal para a massa"})
+	indice.IndexarPublicacao(modelos.Publicacao{ID: 15, Titulo: "Bolo", Conteudo: "simples"})
+
+	for _, teste := range testes {
+		t.Run(teste.nome, func(t *testing.T) {
+			var obtido []uint64
+			for _, resultado := range indice.BuscarPublicacoes(teste.consulta, teste.limite, teste.deslocamento) {
+				obtido = append(obtido, resultado.ID)
+			}
+
+			if !iguais(obtido, teste.esperado) {
+				t.Errorf("BuscarPublicacoes(%q) = %v, esperado %v", teste.consulta, obtido, teste.esperado)
+			}
+		})
+	}
+}
+
+func TestRemoverEReindexar(t *testing.T) {
+	testes := []struct {
+		nome        string
+		alterar     func(indice *Indice)
+		usuarios    map[string][]uint64
+		publicacoes map[string][]uint64
+	}{
+		{
+			nome:        "usuário removido",
+			alterar:     func(indice *Indice) { indice.RemoverUsuario(1) },
+			usuarios:    map[string][]uint64{"joao": nil, "joaosilva": nil, "silva": nil},
+			publicacoes: map[string][]uint64{"cafe": {12, 10}},
+		},
+		{
+			nome: "usuário reindexado com outro nome e nick",
+			alterar: func(indice *Indice) {
+				indice.IndexarUsuario(modelos.Usuario{ID: 1, Nome: "João Pereira", Nick: "jpereira"})
+			},
+			usuarios:    map[string][]uint64{"silva": nil, "joaosilva": nil, "pereira": {1}, "jper": {1}, "joão": {1}},
+			publicacoes: map[string][]uint64{"cafe": {12, 10}},
+		},
+		{
+			nome:        "publicação removida",
+			alterar:     func(indice *Indice) { indice.RemoverPublicacao(10) },
+			usuarios:    map[string][]uint64{"joao": {1}},
+			publicacoes: map[string][]uint64{"cafe": {12}, "manha": nil, "pao": {11}},
+		},
+		{
+			nome: "publicação reindexada com outro texto",
+			alterar: func(indice *Indice) {
+				indice.IndexarPublicacao(modelos.Publicacao{ID: 10, Titulo: "Chá", Conteudo: "chá gelado"})
+			},
+			usuarios:    map[string][]uint64{"joao": {1}},
+			publicacoes: map[string][]uint64{"cafe": {12}, "cha": {10}, "gelado": {10}},
+		},
+		{
+			nome:        "limpar",
+			alterar:     func(indice *Indice) { indice.Limpar() },
+			usuarios:    map[string][]uint64{"joao": nil, "mari": nil},
+			publicacoes: map[string][]uint64{"cafe": nil},
+		},
+	}
+
+	for _, teste := range testes {
+		t.Run(teste.nome, func(t *testing.T) {
+			indice := indiceDeTeste()
+			teste.alterar(indice)
+
+			for consulta, esperado := range teste.usuarios {
+				if obtido := indice.BuscarUsuarios(consulta, 10); !iguais(obtido, esperado) {
+					t.Errorf("BuscarUsuarios(%q) = %v, esperado %v", consulta, obtido, esperado)
+				}
+			}
+
+			for consulta, esperado := range teste.publicacoes {
+				var obtido []uint64
+				for _, resultado := range indice.BuscarPublicacoes(consulta, 10, 0) {
+					obtido = append(obtido, resultado.ID)
+				}
+
+				if !iguais(obtido, esperado) {
+					t.Errorf("BuscarPublicacoes(%q) = %v, esperado %v", consulta, obtido, esperado)
+				}
+			}
+		})
+	}
+}
+
+// buscadorDeReserva registra as buscas que recebe
+type buscadorDeReserva struct {
+	*Indice
+	buscas int
+}
+
+func (reserva *buscadorDeReserva) BuscarUsuarios(consulta string, limite int) []uint64 {
+	reserva.buscas++
+	return []uint64{99}
+}
+
+func TestIndiceComReserva(t *testing.T) {
+	testes := []struct {
+		nome     string
+		preparar func(indice *Indice)
+		esperado []uint64
+		reserva  int
+	}{
+		{"índice ainda não carregado usa a reserva", func(indice *Indice) {}, []uint64{99}, 1},
+		{"índice concluído usa o índice", func(indice *Indice) { indice.Concluir() }, []uint64{1}, 0},
+		{"índice limpo para reconstrução usa a reserva", func(indice *Indice) {
+			indice.Concluir()
+			indice.Limpar()
+		}, []uint64{99}, 1},
+	}
+
+	for _, teste := range testes {
+		t.Run(teste.nome, func(t *testing.T) {
+			reserva := &buscadorDeReserva{Indice: NovoIndice()}
+			buscador := &IndiceComReserva{Indice: NovoIndice(), Reserva: reserva}
+			buscador.IndexarUsuario(modelos.Usuario{ID: 1, Nome: "João Silva", Nick: "joaosilva"})
+			teste.preparar(buscador.Indice)
+
+			if obtido := buscador.BuscarUsuarios("joao", 10); !iguais(obtido, teste.esperado) {
+				t.Errorf("BuscarUsuarios = %v, esperado %v", obtido, teste.esperado)
+			}
+
+			if reserva.buscas != teste.reserva {
+				t.Errorf("a reserva recebeu %d buscas, esperado %d", reserva.buscas, teste.reserva)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package busca
+
+import (
+	"api/src/banco"
+	"api/src/modelos"
+	"log"
+	"strings"
+)
+
+// BuscadorSQL busca direto no banco: os usuários pelo nome e pelo início do nick e as publicações
+// pelo índice FULLTEXT de titulo e conteudo. O próprio banco mantém os dados atualizados, então
+// indexar, remover, limpar e concluir não fazem nada
+type BuscadorSQL struct{}
+
+// IndexarUsuario não faz nada, o banco já tem o usuário
+func (BuscadorSQL) IndexarUsuario(modelos.Usuario) {}
+
+// RemoverUsuario não faz nada, o banco já reflete a exclusão
+func (BuscadorSQL) RemoverUsuario(uint64) {}
+
+// IndexarPublicacao não faz nada, o índice FULLTEXT é atualizado pelo banco
+func (BuscadorSQL) IndexarPublicacao(modelos.Publicacao) {}
+
+// RemoverPublicacao não faz nada, o banco já reflete a exclusão
+func (BuscadorSQL) RemoverPublicacao(uint64) {}
+
+// Limpar não faz nada
+func (BuscadorSQL) Limpar() {}
+
+// Concluir não faz nada
+func (BuscadorSQL) Concluir() {}
+
+// BuscarUsuarios retorna os usuários cujo nome contém todos os termos ou cujo nick começa por
+// eles, com o nick exato primeiro e depois os nicks que começam pela consulta
+func (BuscadorSQL) BuscarUsuarios(consulta string, limite int) []uint64 {
+	termos := modelos.ExtrairTermos(consulta)
+	if len(termos) == 0 {
+		return nil
+	}
+
+	var condicoes []string
+	var parametros []interface{}
+	for _, termo := range termos {
+		condicoes = append(condicoes, "(nome like concat('%', ?, '%') or nick like concat(?, '%'))")
+		parametros = append(parametros, termo, termo)
+	}
+
+	nick := NormalizarNick(consulta)
+	parametros = append(parametros, nick, nick, limite)
+
+	return buscarIDs(`
+	select id, 0 from usuarios
+	where deletadoEm is null and `+strings.Join(condicoes, " and ")+`
+	order by nick = ? desc, nick like concat(?, '%') desc, id
+	limit ?`, parametros...)
+}
+
+// BuscarPublicacoes retorna as publicações não excluídas com todos os termos, usando a relevância
+// calculada pelo FULLTEXT
+func (BuscadorSQL) BuscarPublicacoes(consulta string, limite, deslocamento int) []Resultado {
+	termos := modelos.ExtrairTermos(consulta)
+	if len(termos) == 0 {
+		return nil
+	}
+
+	expressao := "+" + strings.Join(termos, " +")
+
+	ids, relevancias := buscarComRelevancia(`
+	select id, match(titulo, conteudo) against (? in boolean mode) as relevancia from publicacoes
+	where match(titulo, conteudo) against (? in boolean mode) and deletadaEm is null
+	order by relevancia desc, id desc
+	limit ? offset ?`, expressao, expressao, limite, deslocamento)
+
+	resultados := make([]Resultado, 0, len(ids))
+	for i, id := range ids {
+		resultados = append(resultados, Resultado{ID: id, Relevancia: relevancias[i]})
+	}
+
+	return resultados
+}
+
+func buscarIDs(consulta string, parametros ...interface{}) []uint64 {
+	ids, _ := buscarComRelevancia(consulta, parametros...)
+	return ids
+}
+
+// buscarComRelevancia executa uma consulta que retorna id e relevância. Como o Buscador não
+// retorna erros, uma falha é registrada no log e a busca fica sem resultados
+func buscarComRelevancia(consulta string, parametros ...interface{}) ([]uint64, []float64) {
+	db, erro := banco.Conectar()
+	if erro != nil {
+		log.Printf("erro ao buscar no banco: %v", erro)
+		return nil, nil
+	}
+
+	defer db.Close()
+
+	linhas, erro := db.Query(consulta, parametros...)
+	if erro != nil {
+		log.Printf("erro ao buscar no banco: %v", erro)
+		return nil, nil
+	}
+
+	defer linhas.Close()
+
+	var ids []uint64
+	var relevancias []float64
+
+	for linhas.Next() {
+		var id uint64
+		var relevancia float64
+
+		if erro = linhas.Scan(&id, &relevancia); erro != nil {
+			log.Printf("erro ao buscar no banco: %v", erro)
+			return nil, nil
+		}
+
+		ids = append(ids, id)
+		relevancias = append(relevancias, relevancia)
+	}
+
+	if erro = linhas.Err(); erro != nil {
+		log.Printf("erro ao buscar no banco: %v", erro)
+		return nil, nil
+	}
+
+	return ids, relevancias
+}
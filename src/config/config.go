@@ -5,6 +5,8 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -13,6 +15,34 @@ var (
 	StringConexaoBanco = ""
 	Porta              = 0
 	SecretKey          []byte
+
+	// PrazoRestauracaoConta é por quanto tempo uma conta excluída ainda pode ser restaurada
+	PrazoRestauracaoConta = 30 * 24 * time.Hour
+	// PrazoRestauracaoPublicacao é por quanto tempo uma publicação excluída ainda pode ser restaurada
+	PrazoRestauracaoPublicacao = 5 * time.Minute
+	// IntervaloExpurgo é de quanto em quanto tempo os registros excluídos com prazo vencido são apagados
+	IntervaloExpurgo = time.Minute
+	// IntervaloAgendamento é de quanto em quanto tempo as publicações agendadas são verificadas
+	IntervaloAgendamento = 30 * time.Second
+	// IntervaloTendencias é de quanto em quanto tempo as tendências são recalculadas
+	IntervaloTendencias = 5 * time.Minute
+	// JanelaNotificacoes é o intervalo em que notificações do mesmo tipo sobre o mesmo alvo são agrupadas
+	JanelaNotificacoes = 24 * time.Hour
+	// JanelaVisualizacoes é o intervalo em que um mesmo leitor conta uma única impressão e uma
+	// única visualização de cada publicação
+	JanelaVisualizacoes = 30 * time.Minute
+	// IntervaloGravacaoVisualizacoes é de quanto em quanto tempo as visualizações acumuladas em memória são gravadas
+	IntervaloGravacaoVisualizacoes = time.Minute
+	// IntervaloReconstrucaoIndice é quanto se espera para tentar de novo quando a reconstrução do
+	// índice de busca falha. Enquanto isso, as buscas são feitas no banco
+	IntervaloReconstrucaoIndice = time.Minute
+	// ValidadeSugestoes é por quanto tempo as sugestões de usuários calculadas ficam guardadas
+	ValidadeSugestoes = 30 * time.Minute
+	// OrigensPermitidas são as origens (esquema://host[:porta]) de sites que podem abrir conexões
+	// WebSocket com a API, além da própria API
+	OrigensPermitidas []string
+	// Reacoes são os tipos de reação que podem ser deixados em uma publicação
+	Reacoes = []string{"👍", "❤️", "🎉", "😂", "🤔", "🚀"}
 )
 
 // Carregar vai inicializar as variaveis de ambiente
@@ -28,10 +58,54 @@ func Carregar() {
 		Porta = 9000
 	}
 
-	StringConexaoBanco = fmt.Sprintf("%s:%s@/%s?charset=utf8&parseTime=True&loc=Local",
+	StringConexaoBanco = fmt.Sprintf("%s:%s@/%s?charset=utf8mb4&parseTime=True&loc=Local",
 		os.Getenv("DB_USUARIO"),
 		os.Getenv("DB_SENHA"),
 		os.Getenv("DB_NOME"))
 
 	SecretKey = []byte(os.Getenv("SECRET_KEY"))
+
+	if dias, erro := strconv.Atoi(os.Getenv("PRAZO_RESTAURACAO_CONTA_DIAS")); erro == nil && dias > 0 {
+		PrazoRestauracaoConta = time.Duration(dias) * 24 * time.Hour
+	}
+
+	if minutos, erro := strconv.Atoi(os.Getenv("PRAZO_RESTAURACAO_PUBLICACAO_MINUTOS")); erro == nil && minutos > 0 {
+		PrazoRestauracaoPublicacao = time.Duration(minutos) * time.Minute
+	}
+
+	if segundos, erro := strconv.Atoi(os.Getenv("INTERVALO_EXPURGO_SEGUNDOS")); erro == nil && segundos > 0 {
+		IntervaloExpurgo = time.Duration(segundos) * time.Second
+	}
+
+	if segundos, erro := strconv.Atoi(os.Getenv("INTERVALO_AGENDAMENTO_SEGUNDOS")); erro == nil && segundos > 0 {
+		IntervaloAgendamento = time.Duration(segundos) * time.Second
+	}
+
+	if segundos, erro := strconv.Atoi(os.Getenv("INTERVALO_TENDENCIAS_SEGUNDOS")); erro == nil && segundos > 0 {
+		IntervaloTendencias = time.Duration(segundos) * time.Second
+	}
+
+	if horas, erro := strconv.Atoi(os.Getenv("JANELA_NOTIFICACOES_HORAS")); erro == nil && horas > 0 {
+		JanelaNotificacoes = time.Duration(horas) * time.Hour
+	}
+
+	if minutos, erro := strconv.Atoi(os.Getenv("JANELA_VISUALIZACOES_MINUTOS")); erro == nil && minutos > 0 {
+		JanelaVisualizacoes = time.Duration(minutos) * time.Minute
+	}
+
+	if segundos, erro := strconv.Atoi(os.Getenv("INTERVALO_VISUALIZACOES_SEGUNDOS")); erro == nil && segundos > 0 {
+		IntervaloGravacaoVisualizacoes = time.Duration(segundos) * time.Second
+	}
+
+	if minutos, erro := strconv.Atoi(os.Getenv("VALIDADE_SUGESTOES_MINUTOS")); erro == nil && minutos > 0 {
+		ValidadeSugestoes = time.Duration(minutos) * time.Minute
+	}
+
+	if origens := strings.Fields(strings.ReplaceAll(os.Getenv("ORIGENS_PERMITIDAS"), ",", " ")); len(origens) > 0 {
+		OrigensPermitidas = origens
+	}
+
+	if reacoes := strings.Fields(strings.ReplaceAll(os.Getenv("REACOES"), ",", " ")); len(reacoes) > 0 {
+		Reacoes = reacoes
+	}
 }
@@ -0,0 +1,176 @@
+package controllers
+
+import (
+	"api/src/autenticacao"
+	"api/src/banco"
+	"api/src/busca"
+	"api/src/modelos"
+	"api/src/repositorios"
+	"api/src/respostas"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// AlterarEstadoConta permite que um administrador ative, suspenda, bane ou desative uma conta
+func AlterarEstadoConta(w http.ResponseWriter, r *http.Request) {
+	administradorID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	usuarioId, erro := strconv.ParseUint(parametros["usuarioId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	if usuarioId == administradorID {
+		respostas.Erro(w, http.StatusBadRequest, errors.New("não é possivel alterar o estado da sua própria conta"))
+		return
+	}
+
+	corpoRequisicao, erro := ioutil.ReadAll(r.Body)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnprocessableEntity, erro)
+		return
+	}
+
+	var estado modelos.EstadoConta
+	if erro = json.Unmarshal(corpoRequisicao, &estado); erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	if erro = estado.Preparar(); erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	if !exigirAdministrador(w, db, administradorID) {
+		return
+	}
+
+	if !exigirPerfilAcima(w, db, administradorID, usuarioId) {
+		return
+	}
+
+	repositorio := repositorios.NovoRepositorioDeUsuarios(db)
+	if _, erro = repositorio.BuscarEstado(usuarioId); erro != nil {
+		if erro == sql.ErrNoRows {
+			respostas.Erro(w, http.StatusNotFound, errors.New("usuário não encontrado"))
+			return
+		}
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	estado.AlteradoPor = administradorID
+	if erro = repositorio.AlterarEstado(usuarioId, estado); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+// BuscarHistoricoEstadosConta retorna as alterações de estado da conta de um usuário
+func BuscarHistoricoEstadosConta(w http.ResponseWriter, r *http.Request) {
+	administradorID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	usuarioId, erro := strconv.ParseUint(parametros["usuarioId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	if !exigirAdministrador(w, db, administradorID) {
+		return
+	}
+
+	repositorio := repositorios.NovoRepositorioDeUsuarios(db)
+	historico, erro := repositorio.BuscarHistoricoEstados(usuarioId)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusOK, historico)
+}
+
+// ReconstruirIndiceDeBusca indexa novamente todos os usuários e publicações no buscador
+func ReconstruirIndiceDeBusca(w http.ResponseWriter, r *http.Request) {
+	administradorID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	if !exigirAdministrador(w, db, administradorID) {
+		return
+	}
+
+	usuarios, publicacoes, erro := repositorios.ReconstruirIndice(db, busca.Padrao)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusOK, struct {
+		Usuarios    uint64 `json:"usuarios"`
+		Publicacoes uint64 `json:"publicacoes"`
+	}{usuarios, publicacoes})
+}
+
+// exigirAdministrador verifica se o usuário é administrador, respondendo 403 caso não seja
+func exigirAdministrador(w http.ResponseWriter, db *sql.DB, usuarioID uint64) bool {
+	repositorio := repositorios.NovoRepositorioDeUsuarios(db)
+	perfil, erro := repositorio.BuscarPerfil(usuarioID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return false
+	}
+
+	if perfil != modelos.PerfilAdministrador {
+		respostas.Erro(w, http.StatusForbidden, errors.New("acesso restrito a administradores"))
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"api/src/autenticacao"
+	"api/src/banco"
+	"api/src/modelos"
+	"api/src/repositorios"
+	"api/src/respostas"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// formatoDataBusca é o formato das datas usadas para filtrar a busca
+const formatoDataBusca = "2006-01-02"
+
+// Buscar pesquisa as publicações pelo termo informado em q, podendo filtrar por autor, tag
+// e período (de e ate, inclusive)
+func Buscar(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	filtro, erro := extrairFiltroBusca(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	limite, deslocamento := extrairPaginacao(r)
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	repositorio := repositorios.NovoRepositorioDePublicacoes(db)
+	resultados, erro := repositorio.Pesquisar(filtro, usuarioID, limite, deslocamento)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusOK, resultados)
+}
+
+func extrairFiltroBusca(r *http.Request) (modelos.FiltroBusca, error) {
+	parametros := r.URL.Query()
+
+	filtro := modelos.FiltroBusca{
+		Termo:     strings.TrimSpace(parametros.Get("q")),
+		AutorNick: strings.TrimPrefix(strings.TrimSpace(parametros.Get("autor")), "@"),
+	}
+
+	if filtro.Termo == "" {
+		return modelos.FiltroBusca{}, errors.New("informe o que deseja buscar")
+	}
+
+	if tag := parametros.Get("tag"); tag != "" {
+		if filtro.Tag = modelos.NormalizarTag(tag); filtro.Tag == "" {
+			return modelos.FiltroBusca{}, errors.New("tag inválida")
+		}
+	}
+
+	if de := parametros.Get("de"); de != "" {
+		data, erro := time.ParseInLocation(formatoDataBusca, de, time.Local)
+		if erro != nil {
+			return modelos.FiltroBusca{}, errors.New("data inicial inválida, use o formato AAAA-MM-DD")
+		}
+		filtro.De = &data
+	}
+
+	if ate := parametros.Get("ate"); ate != "" {
+		data, erro := time.ParseInLocation(formatoDataBusca, ate, time.Local)
+		if erro != nil {
+			return modelos.FiltroBusca{}, errors.New("data final inválida, use o formato AAAA-MM-DD")
+		}
+		data = data.AddDate(0, 0, 1)
+		filtro.Ate = &data
+	}
+
+	return filtro, nil
+}
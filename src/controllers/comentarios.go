@@ -0,0 +1,218 @@
+package controllers
+
+import (
+	"api/src/autenticacao"
+	"api/src/banco"
+	"api/src/divulgacao"
+	"api/src/modelos"
+	"api/src/repositorios"
+	"api/src/respostas"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// ComentarPublicacao registra um comentário do usuário logado em uma publicação e avisa o autor
+func ComentarPublicacao(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	publicacaoId, erro := strconv.ParseUint(parametros["publicacaoId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	corpoRequisicao, erro := ioutil.ReadAll(r.Body)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnprocessableEntity, erro)
+		return
+	}
+
+	var comentario modelos.Comentario
+	if erro = json.Unmarshal(corpoRequisicao, &comentario); erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	if erro = comentario.Preparar(); erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	repositorioPublicacoes := repositorios.NovoRepositorioDePublicacoes(db)
+	podeVer, erro := repositorioPublicacoes.PodeVer(publicacaoId, usuarioID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	publicacao, erro := repositorioPublicacoes.BuscarPublicacao(publicacaoId)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if !podeVer || publicacao.ID == 0 {
+		respostas.Erro(w, http.StatusNotFound, errors.New("publicação não encontrada"))
+		return
+	}
+
+	bloqueado, erro := repositorios.NovoRepositorioDeUsuarios(db).ExisteBloqueio(usuarioID, publicacao.AutorID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if bloqueado {
+		respostas.Erro(w, http.StatusNotFound, errors.New("publicação não encontrada"))
+		return
+	}
+
+	comentario.PublicacaoID = publicacaoId
+	comentario.AutorID = usuarioID
+
+	repositorio := repositorios.NovoRepositorioDeComentarios(db)
+	comentario.ID, erro = repositorio.Criar(comentario)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if erro = divulgacao.Notificar(db, publicacao.AutorID, usuarioID, modelos.NotificacaoComentario, publicacaoId); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	comentario, erro = repositorio.BuscarPorID(comentario.ID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusCreated, comentario)
+}
+
+// BuscarComentarios lista os comentários de uma publicação que o usuário logado pode ver
+func BuscarComentarios(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	publicacaoId, erro := strconv.ParseUint(parametros["publicacaoId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	limite, deslocamento := extrairPaginacao(r)
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	podeVer, erro := repositorios.NovoRepositorioDePublicacoes(db).PodeVer(publicacaoId, usuarioID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if !podeVer {
+		respostas.Erro(w, http.StatusNotFound, errors.New("publicação não encontrada"))
+		return
+	}
+
+	repositorio := repositorios.NovoRepositorioDeComentarios(db)
+	comentarios, erro := repositorio.Buscar(publicacaoId, usuarioID, limite, deslocamento)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusOK, comentarios)
+}
+
+// DeletarComentario apaga um comentário. Podem apagá-lo quem o escreveu e o autor da publicação
+func DeletarComentario(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	publicacaoId, erro := strconv.ParseUint(parametros["publicacaoId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	comentarioId, erro := strconv.ParseUint(parametros["comentarioId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	repositorio := repositorios.NovoRepositorioDeComentarios(db)
+	comentario, erro := repositorio.BuscarPorID(comentarioId)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if comentario.ID == 0 || comentario.PublicacaoID != publicacaoId {
+		respostas.Erro(w, http.StatusNotFound, errors.New("comentário não encontrado"))
+		return
+	}
+
+	if comentario.AutorID != usuarioID {
+		publicacao, erro := repositorios.NovoRepositorioDePublicacoes(db).BuscarPublicacao(publicacaoId)
+		if erro != nil {
+			respostas.Erro(w, http.StatusInternalServerError, erro)
+			return
+		}
+
+		if publicacao.AutorID != usuarioID {
+			respostas.Erro(w, http.StatusForbidden, errors.New("não é possível apagar o comentário de outro usuário"))
+			return
+		}
+	}
+
+	if erro = repositorio.Deletar(comentarioId); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
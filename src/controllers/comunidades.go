@@ -0,0 +1,571 @@
+package controllers
+
+import (
+	"api/src/autenticacao"
+	"api/src/banco"
+	"api/src/modelos"
+	"api/src/repositorios"
+	"api/src/respostas"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// CriarComunidade cria uma comunidade tendo o usuário logado como dono
+func CriarComunidade(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	corpoRequisicao, erro := ioutil.ReadAll(r.Body)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnprocessableEntity, erro)
+		return
+	}
+
+	var comunidade modelos.Comunidade
+	if erro = json.Unmarshal(corpoRequisicao, &comunidade); erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	if erro = comunidade.Preparar(); erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	comunidade.DonoID = usuarioID
+	comunidade.Membros = 1
+
+	repositorio := repositorios.NovoRepositorioDeComunidades(db)
+	comunidade.ID, erro = repositorio.Criar(comunidade)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusCreated, comunidade)
+}
+
+// BuscarComunidades retorna as comunidades visíveis para o usuário logado
+func BuscarComunidades(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	nome := r.URL.Query().Get("nome")
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	repositorio := repositorios.NovoRepositorioDeComunidades(db)
+	comunidades, erro := repositorio.Buscar(usuarioID, nome)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusOK, comunidades)
+}
+
+// BuscarComunidade retorna uma comunidade
+func BuscarComunidade(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	comunidade, _, ok := carregarComunidade(w, r, db, usuarioID)
+	if !ok {
+		return
+	}
+
+	respostas.JSON(w, http.StatusOK, comunidade)
+}
+
+// AtualizarComunidade altera os dados de uma comunidade; apenas o dono pode fazê-lo
+func AtualizarComunidade(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	corpoRequisicao, erro := ioutil.ReadAll(r.Body)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnprocessableEntity, erro)
+		return
+	}
+
+	var comunidade modelos.Comunidade
+	if erro = json.Unmarshal(corpoRequisicao, &comunidade); erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	if erro = comunidade.Preparar(); erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	comunidadeSalvaNoBanco, papel, ok := carregarComunidade(w, r, db, usuarioID)
+	if !ok {
+		return
+	}
+
+	if papel != modelos.PapelDono {
+		respostas.Erro(w, http.StatusForbidden, errors.New("apenas o dono pode alterar a comunidade"))
+		return
+	}
+
+	repositorio := repositorios.NovoRepositorioDeComunidades(db)
+	if erro = repositorio.Atualizar(comunidadeSalvaNoBanco.ID, comunidade); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+// DeletarComunidade apaga uma comunidade e suas publicações; apenas o dono pode fazê-lo
+func DeletarComunidade(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	comunidade, papel, ok := carregarComunidade(w, r, db, usuarioID)
+	if !ok {
+		return
+	}
+
+	if papel != modelos.PapelDono {
+		respostas.Erro(w, http.StatusForbidden, errors.New("apenas o dono pode apagar a comunidade"))
+		return
+	}
+
+	repositorio := repositorios.NovoRepositorioDeComunidades(db)
+	if erro = repositorio.Deletar(comunidade.ID); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+// EntrarNaComunidade adiciona o usuário logado à comunidade. Comunidades privadas exigem convite
+func EntrarNaComunidade(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	comunidadeId, erro := strconv.ParseUint(parametros["comunidadeId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	repositorio := repositorios.NovoRepositorioDeComunidades(db)
+	comunidade, erro := repositorio.BuscarPorID(comunidadeId)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if comunidade.ID == 0 {
+		respostas.Erro(w, http.StatusNotFound, errors.New("comunidade não encontrada"))
+		return
+	}
+
+	if comunidade.Privada {
+		convidado, erro := repositorio.ExisteConvite(comunidadeId, usuarioID)
+		if erro != nil {
+			respostas.Erro(w, http.StatusInternalServerError, erro)
+			return
+		}
+
+		if !convidado {
+			respostas.Erro(w, http.StatusNotFound, errors.New("comunidade não encontrada"))
+			return
+		}
+	}
+
+	if erro = repositorio.Entrar(comunidadeId, usuarioID); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+// SairDaComunidade remove o usuário logado da comunidade
+func SairDaComunidade(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	comunidade, papel, ok := carregarComunidade(w, r, db, usuarioID)
+	if !ok {
+		return
+	}
+
+	if papel == modelos.PapelDono {
+		respostas.Erro(w, http.StatusBadRequest, errors.New("o dono não pode sair da comunidade"))
+		return
+	}
+
+	repositorio := repositorios.NovoRepositorioDeComunidades(db)
+	if erro = repositorio.RemoverMembro(comunidade.ID, usuarioID); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+// ConvidarParaComunidade convida um usuário para a comunidade; apenas moderadores podem convidar
+func ConvidarParaComunidade(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	convidadoId, erro := strconv.ParseUint(parametros["usuarioId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	comunidade, papel, ok := carregarComunidade(w, r, db, usuarioID)
+	if !ok {
+		return
+	}
+
+	if !modelos.PodeModerar(papel) {
+		respostas.Erro(w, http.StatusForbidden, errors.New("apenas moderadores podem convidar para a comunidade"))
+		return
+	}
+
+	repositorioUsuarios := repositorios.NovoRepositorioDeUsuarios(db)
+	bloqueado, erro := repositorioUsuarios.ExisteBloqueio(usuarioID, convidadoId)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if bloqueado {
+		respostas.Erro(w, http.StatusForbidden, errors.New("não é possivel convidar este usuário"))
+		return
+	}
+
+	repositorio := repositorios.NovoRepositorioDeComunidades(db)
+	if erro = repositorio.Convidar(comunidade.ID, convidadoId, usuarioID); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+// BuscarMembrosDaComunidade retorna os membros de uma comunidade
+func BuscarMembrosDaComunidade(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	comunidade, _, ok := carregarComunidade(w, r, db, usuarioID)
+	if !ok {
+		return
+	}
+
+	repositorio := repositorios.NovoRepositorioDeComunidades(db)
+	membros, erro := repositorio.BuscarMembros(comunidade.ID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusOK, membros)
+}
+
+// RemoverMembroDaComunidade expulsa um membro; moderadores só podem ser removidos pelo dono
+func RemoverMembroDaComunidade(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	membroId, erro := strconv.ParseUint(parametros["usuarioId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	comunidade, papel, ok := carregarComunidade(w, r, db, usuarioID)
+	if !ok {
+		return
+	}
+
+	repositorio := repositorios.NovoRepositorioDeComunidades(db)
+	papelDoMembro, erro := repositorio.BuscarPapel(comunidade.ID, membroId)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if !modelos.PodeModerar(papel) || papelDoMembro == modelos.PapelDono ||
+		(papelDoMembro == modelos.PapelModerador && papel != modelos.PapelDono) {
+		respostas.Erro(w, http.StatusForbidden, errors.New("não é possivel remover este membro"))
+		return
+	}
+
+	if erro = repositorio.RemoverMembro(comunidade.ID, membroId); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+// PromoverModerador torna um membro moderador; apenas o dono pode fazê-lo
+func PromoverModerador(w http.ResponseWriter, r *http.Request) {
+	definirPapelNaComunidade(w, r, modelos.PapelModerador)
+}
+
+// RebaixarModerador torna um moderador membro comum; apenas o dono pode fazê-lo
+func RebaixarModerador(w http.ResponseWriter, r *http.Request) {
+	definirPapelNaComunidade(w, r, modelos.PapelMembro)
+}
+
+// BuscarPublicacoesDaComunidade retorna o feed de uma comunidade
+func BuscarPublicacoesDaComunidade(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	comunidade, _, ok := carregarComunidade(w, r, db, usuarioID)
+	if !ok {
+		return
+	}
+
+	repositorio := repositorios.NovoRepositorioDeComunidades(db)
+	publicacoes, erro := repositorio.BuscarPublicacoes(comunidade.ID, usuarioID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusOK, publicacoes)
+}
+
+func definirPapelNaComunidade(w http.ResponseWriter, r *http.Request, novoPapel string) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	membroId, erro := strconv.ParseUint(parametros["usuarioId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	comunidade, papel, ok := carregarComunidade(w, r, db, usuarioID)
+	if !ok {
+		return
+	}
+
+	if papel != modelos.PapelDono {
+		respostas.Erro(w, http.StatusForbidden, errors.New("apenas o dono pode alterar os moderadores"))
+		return
+	}
+
+	repositorio := repositorios.NovoRepositorioDeComunidades(db)
+	papelDoMembro, erro := repositorio.BuscarPapel(comunidade.ID, membroId)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if papelDoMembro == "" || papelDoMembro == modelos.PapelDono {
+		respostas.Erro(w, http.StatusBadRequest, errors.New("o usuário não é um membro que possa ter o papel alterado"))
+		return
+	}
+
+	if erro = repositorio.DefinirPapel(comunidade.ID, membroId, novoPapel); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+// carregarComunidade busca a comunidade da rota e o papel do usuário nela. Comunidades privadas
+// são tratadas como inexistentes para quem não é membro. Em caso de falha, a resposta já foi escrita
+func carregarComunidade(w http.ResponseWriter, r *http.Request, db *sql.DB, usuarioID uint64) (modelos.Comunidade, string, bool) {
+	parametros := mux.Vars(r)
+	comunidadeId, erro := strconv.ParseUint(parametros["comunidadeId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return modelos.Comunidade{}, "", false
+	}
+
+	repositorio := repositorios.NovoRepositorioDeComunidades(db)
+	comunidade, erro := repositorio.BuscarPorID(comunidadeId)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return modelos.Comunidade{}, "", false
+	}
+
+	papel, erro := repositorio.BuscarPapel(comunidadeId, usuarioID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return modelos.Comunidade{}, "", false
+	}
+
+	if comunidade.ID == 0 || (comunidade.Privada && papel == "") {
+		respostas.Erro(w, http.StatusNotFound, errors.New("comunidade não encontrada"))
+		return modelos.Comunidade{}, "", false
+	}
+
+	return comunidade, papel, true
+}
+
+// podeModerarPublicacao indica se o usuário pode alterar ou apagar a publicação:
+// o autor sempre pode, e moderadores da comunidade podem nas publicações dela
+func podeModerarPublicacao(db *sql.DB, usuarioID uint64, publicacao modelos.Publicacao) (bool, error) {
+	if publicacao.AutorID == usuarioID {
+		return true, nil
+	}
+
+	if publicacao.ComunidadeID == 0 {
+		return false, nil
+	}
+
+	repositorio := repositorios.NovoRepositorioDeComunidades(db)
+	papel, erro := repositorio.BuscarPapel(publicacao.ComunidadeID, usuarioID)
+	if erro != nil {
+		return false, erro
+	}
+
+	return modelos.PodeModerar(papel), nil
+}
@@ -0,0 +1,368 @@
+package controllers
+
+import (
+	"api/src/autenticacao"
+	"api/src/banco"
+	"api/src/eventos"
+	"api/src/modelos"
+	"api/src/repositorios"
+	"api/src/respostas"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// CriarConversa inicia uma conversa do usuário logado com os participantes informados.
+// Só é possivel conversar com quem segue o usuário logado e não há bloqueio entre eles
+func CriarConversa(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	corpoRequisicao, erro := ioutil.ReadAll(r.Body)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnprocessableEntity, erro)
+		return
+	}
+
+	var requisicao struct {
+		Participantes []uint64 `json:"participantes"`
+	}
+	if erro = json.Unmarshal(corpoRequisicao, &requisicao); erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	var participantes []uint64
+	incluidos := map[uint64]bool{usuarioID: true}
+	for _, participanteID := range requisicao.Participantes {
+		if !incluidos[participanteID] {
+			incluidos[participanteID] = true
+			participantes = append(participantes, participanteID)
+		}
+	}
+
+	if len(participantes) == 0 {
+		respostas.Erro(w, http.StatusBadRequest, errors.New("informe ao menos um participante além de você"))
+		return
+	}
+
+	if len(participantes)+1 > modelos.MaximoParticipantesConversa {
+		respostas.Erro(w, http.StatusBadRequest,
+			fmt.Errorf("uma conversa pode ter no máximo %d participantes", modelos.MaximoParticipantesConversa))
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	repositorioUsuarios := repositorios.NovoRepositorioDeUsuarios(db)
+	for _, participanteID := range participantes {
+		participante, erro := repositorioUsuarios.BuscarUsuarioPorID(participanteID)
+		if erro != nil {
+			respostas.Erro(w, http.StatusInternalServerError, erro)
+			return
+		}
+
+		if participante.ID == 0 {
+			respostas.Erro(w, http.StatusNotFound, fmt.Errorf("usuário %d não encontrado", participanteID))
+			return
+		}
+
+		bloqueado, erro := repositorioUsuarios.ExisteBloqueio(usuarioID, participanteID)
+		if erro != nil {
+			respostas.Erro(w, http.StatusInternalServerError, erro)
+			return
+		}
+
+		segue, erro := repositorioUsuarios.Segue(usuarioID, participanteID)
+		if erro != nil {
+			respostas.Erro(w, http.StatusInternalServerError, erro)
+			return
+		}
+
+		if bloqueado || !segue {
+			respostas.Erro(w, http.StatusForbidden,
+				fmt.Errorf("não é possivel iniciar uma conversa com %s", participante.Nick))
+			return
+		}
+	}
+
+	repositorio := repositorios.NovoRepositorioDeConversas(db)
+
+	var conversa modelos.Conversa
+	statusCode := http.StatusCreated
+
+	if len(participantes) == 1 {
+		conversa.ID, erro = repositorio.BuscarConversaIndividual(usuarioID, participantes[0])
+		if erro != nil {
+			respostas.Erro(w, http.StatusInternalServerError, erro)
+			return
+		}
+
+		if conversa.ID != 0 {
+			statusCode = http.StatusOK
+		}
+	}
+
+	if conversa.ID == 0 {
+		conversa.ID, erro = repositorio.Criar(usuarioID, participantes)
+		if erro != nil {
+			respostas.Erro(w, http.StatusInternalServerError, erro)
+			return
+		}
+	}
+
+	conversa.Grupo = len(participantes) > 1
+	conversa.Participantes, erro = repositorio.BuscarParticipantes(conversa.ID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, statusCode, conversa)
+}
+
+// BuscarConversas retorna as conversas do usuário logado
+func BuscarConversas(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	limite, deslocamento := extrairPaginacao(r)
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	repositorio := repositorios.NovoRepositorioDeConversas(db)
+	conversas, erro := repositorio.Buscar(usuarioID, limite, deslocamento)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusOK, conversas)
+}
+
+// BuscarMensagens retorna as mensagens de uma conversa do usuário logado
+func BuscarMensagens(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	conversaId, erro := strconv.ParseUint(parametros["conversaId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	limite, deslocamento := extrairPaginacao(r)
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	repositorio := repositorios.NovoRepositorioDeConversas(db)
+	participa, erro := repositorio.EhParticipante(conversaId, usuarioID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if !participa {
+		respostas.Erro(w, http.StatusForbidden, errors.New("você não participa desta conversa"))
+		return
+	}
+
+	mensagens, erro := repositorio.BuscarMensagens(conversaId, limite, deslocamento)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusOK, mensagens)
+}
+
+// EnviarMensagem envia uma mensagem do usuário logado para uma conversa
+func EnviarMensagem(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	conversaId, erro := strconv.ParseUint(parametros["conversaId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	corpoRequisicao, erro := ioutil.ReadAll(r.Body)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnprocessableEntity, erro)
+		return
+	}
+
+	var mensagem modelos.Mensagem
+	if erro = json.Unmarshal(corpoRequisicao, &mensagem); erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	if erro = mensagem.Preparar(); erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	repositorio := repositorios.NovoRepositorioDeConversas(db)
+	participantes, erro := repositorio.BuscarParticipantes(conversaId)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	var destinatarios []uint64
+	participa := false
+	for _, participante := range participantes {
+		destinatarios = append(destinatarios, participante.ID)
+		if participante.ID == usuarioID {
+			participa = true
+		}
+	}
+
+	if !participa {
+		respostas.Erro(w, http.StatusForbidden, errors.New("você não participa desta conversa"))
+		return
+	}
+
+	repositorioUsuarios := repositorios.NovoRepositorioDeUsuarios(db)
+	for _, participante := range participantes {
+		if participante.ID == usuarioID {
+			continue
+		}
+
+		bloqueado, erro := repositorioUsuarios.ExisteBloqueio(usuarioID, participante.ID)
+		if erro != nil {
+			respostas.Erro(w, http.StatusInternalServerError, erro)
+			return
+		}
+
+		if bloqueado {
+			respostas.Erro(w, http.StatusForbidden,
+				fmt.Errorf("não é possivel enviar mensagens para %s", participante.Nick))
+			return
+		}
+	}
+
+	mensagem.ConversaID = conversaId
+	mensagem.AutorID = usuarioID
+
+	mensagem.ID, erro = repositorio.CriarMensagem(mensagem)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	eventos.Publicar(destinatarios, eventos.EventoMensagem, mensagem)
+
+	respostas.JSON(w, http.StatusCreated, mensagem)
+}
+
+// MarcarConversaComoLida registra que o usuário logado leu as mensagens de uma conversa
+func MarcarConversaComoLida(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	conversaId, erro := strconv.ParseUint(parametros["conversaId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	repositorio := repositorios.NovoRepositorioDeConversas(db)
+	if erro = repositorio.MarcarComoLida(conversaId, usuarioID); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+// SairDaConversa remove o usuário logado de uma conversa
+func SairDaConversa(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	conversaId, erro := strconv.ParseUint(parametros["conversaId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	repositorio := repositorios.NovoRepositorioDeConversas(db)
+	if erro = repositorio.Sair(conversaId, usuarioID); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
@@ -0,0 +1,415 @@
+package controllers
+
+import (
+	"api/src/autenticacao"
+	"api/src/banco"
+	"api/src/modelos"
+	"api/src/repositorios"
+	"api/src/respostas"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// DenunciarPublicacao envia uma publicação para a fila de moderação
+func DenunciarPublicacao(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	publicacaoId, erro := strconv.ParseUint(parametros["publicacaoId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	denuncia, ok := lerDenuncia(w, r)
+	if !ok {
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	repositorioPublicacoes := repositorios.NovoRepositorioDePublicacoes(db)
+	podeVer, erro := repositorioPublicacoes.PodeVer(publicacaoId, usuarioID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	publicacao, erro := repositorioPublicacoes.BuscarPublicacao(publicacaoId)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if !podeVer || publicacao.ID == 0 {
+		respostas.Erro(w, http.StatusNotFound, errors.New("publicação não encontrada"))
+		return
+	}
+
+	if publicacao.AutorID == usuarioID {
+		respostas.Erro(w, http.StatusBadRequest, errors.New("não é possivel denunciar sua própria publicação"))
+		return
+	}
+
+	denuncia.DenuncianteID = usuarioID
+	denuncia.UsuarioID = publicacao.AutorID
+	denuncia.PublicacaoID = publicacao.ID
+	denuncia.Status = modelos.DenunciaAberta
+
+	repositorio := repositorios.NovoRepositorioDeDenuncias(db)
+	denuncia.ID, erro = repositorio.Criar(denuncia)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusCreated, denuncia)
+}
+
+// DenunciarUsuario envia um usuário para a fila de moderação
+func DenunciarUsuario(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	denunciadoId, erro := strconv.ParseUint(parametros["usuarioId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	if denunciadoId == usuarioID {
+		respostas.Erro(w, http.StatusBadRequest, errors.New("não é possivel denunciar você mesmo"))
+		return
+	}
+
+	denuncia, ok := lerDenuncia(w, r)
+	if !ok {
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	repositorioUsuarios := repositorios.NovoRepositorioDeUsuarios(db)
+	denunciado, erro := repositorioUsuarios.BuscarUsuarioPorID(denunciadoId)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if denunciado.ID == 0 {
+		respostas.Erro(w, http.StatusNotFound, errors.New("usuário não encontrado"))
+		return
+	}
+
+	denuncia.DenuncianteID = usuarioID
+	denuncia.UsuarioID = denunciado.ID
+	denuncia.Status = modelos.DenunciaAberta
+
+	repositorio := repositorios.NovoRepositorioDeDenuncias(db)
+	denuncia.ID, erro = repositorio.Criar(denuncia)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusCreated, denuncia)
+}
+
+// BuscarDenuncias retorna a fila de moderação, filtrada pela situação
+func BuscarDenuncias(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	limite, deslocamento := extrairPaginacao(r)
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	if !exigirModerador(w, db, usuarioID) {
+		return
+	}
+
+	repositorio := repositorios.NovoRepositorioDeDenuncias(db)
+	denuncias, erro := repositorio.Buscar(status, limite, deslocamento)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusOK, denuncias)
+}
+
+// AtribuirDenuncia coloca a denúncia em análise pelo moderador logado
+func AtribuirDenuncia(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	if !exigirModerador(w, db, usuarioID) {
+		return
+	}
+
+	denuncia, ok := carregarDenunciaAberta(w, r, db)
+	if !ok {
+		return
+	}
+
+	repositorio := repositorios.NovoRepositorioDeDenuncias(db)
+	if erro = repositorio.Atribuir(denuncia.ID, usuarioID); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+// ResolverDenuncia aplica a decisão do moderador logado e encerra a denúncia
+func ResolverDenuncia(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	corpoRequisicao, erro := ioutil.ReadAll(r.Body)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnprocessableEntity, erro)
+		return
+	}
+
+	var decisao modelos.DecisaoModeracao
+	if erro = json.Unmarshal(corpoRequisicao, &decisao); erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	if erro = decisao.Preparar(); erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	if !exigirModerador(w, db, usuarioID) {
+		return
+	}
+
+	denuncia, ok := carregarDenunciaAberta(w, r, db)
+	if !ok {
+		return
+	}
+
+	if decisao.Acao == modelos.AcaoOcultarPublicacao && denuncia.PublicacaoID == 0 {
+		respostas.Erro(w, http.StatusBadRequest, errors.New("a denúncia não se refere a uma publicação"))
+		return
+	}
+
+	if decisao.Acao == modelos.AcaoSuspenderUsuario && denuncia.UsuarioID == 0 {
+		respostas.Erro(w, http.StatusBadRequest, errors.New("o usuário denunciado não existe mais"))
+		return
+	}
+
+	if decisao.Acao != modelos.AcaoDescartar && !exigirPerfilAcima(w, db, usuarioID, denuncia.UsuarioID) {
+		return
+	}
+
+	decisao.DenunciaID = denuncia.ID
+	decisao.ModeradorID = usuarioID
+
+	repositorio := repositorios.NovoRepositorioDeDenuncias(db)
+	decisao.ID, erro = repositorio.Resolver(denuncia, decisao)
+	if erro == repositorios.ErrContaBanida {
+		respostas.Erro(w, http.StatusConflict, erro)
+		return
+	}
+
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusCreated, decisao)
+}
+
+// BuscarDecisoesModeracao retorna o histórico de decisões, opcionalmente de uma denúncia
+func BuscarDecisoesModeracao(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	var denunciaId uint64
+	if valor := r.URL.Query().Get("denuncia"); valor != "" {
+		if denunciaId, erro = strconv.ParseUint(valor, 10, 64); erro != nil {
+			respostas.Erro(w, http.StatusBadRequest, erro)
+			return
+		}
+	}
+
+	limite, deslocamento := extrairPaginacao(r)
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	if !exigirModerador(w, db, usuarioID) {
+		return
+	}
+
+	repositorio := repositorios.NovoRepositorioDeDenuncias(db)
+	decisoes, erro := repositorio.BuscarDecisoes(denunciaId, limite, deslocamento)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusOK, decisoes)
+}
+
+func lerDenuncia(w http.ResponseWriter, r *http.Request) (modelos.Denuncia, bool) {
+	corpoRequisicao, erro := ioutil.ReadAll(r.Body)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnprocessableEntity, erro)
+		return modelos.Denuncia{}, false
+	}
+
+	var denuncia modelos.Denuncia
+	if erro = json.Unmarshal(corpoRequisicao, &denuncia); erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return modelos.Denuncia{}, false
+	}
+
+	if erro = denuncia.Preparar(); erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return modelos.Denuncia{}, false
+	}
+
+	return denuncia, true
+}
+
+// carregarDenunciaAberta busca a denúncia da rota, que ainda não pode ter sido resolvida.
+// Em caso de falha, a resposta já foi escrita
+func carregarDenunciaAberta(w http.ResponseWriter, r *http.Request, db *sql.DB) (modelos.Denuncia, bool) {
+	parametros := mux.Vars(r)
+	denunciaId, erro := strconv.ParseUint(parametros["denunciaId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return modelos.Denuncia{}, false
+	}
+
+	repositorio := repositorios.NovoRepositorioDeDenuncias(db)
+	denuncia, erro := repositorio.BuscarPorID(denunciaId)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return modelos.Denuncia{}, false
+	}
+
+	if denuncia.ID == 0 {
+		respostas.Erro(w, http.StatusNotFound, errors.New("denúncia não encontrada"))
+		return modelos.Denuncia{}, false
+	}
+
+	if denuncia.Status == modelos.DenunciaResolvida {
+		respostas.Erro(w, http.StatusConflict, errors.New("denúncia já resolvida"))
+		return modelos.Denuncia{}, false
+	}
+
+	return denuncia, true
+}
+
+// exigirPerfilAcima verifica se o ator tem perfil acima do alvo, respondendo 403 caso não tenha.
+// Moderadores não agem sobre outros moderadores nem sobre administradores
+func exigirPerfilAcima(w http.ResponseWriter, db *sql.DB, atorID, alvoID uint64) bool {
+	repositorio := repositorios.NovoRepositorioDeUsuarios(db)
+	perfilAtor, erro := repositorio.BuscarPerfil(atorID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return false
+	}
+
+	perfilAlvo, erro := repositorio.BuscarPerfil(alvoID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return false
+	}
+
+	if !modelos.PerfilAcima(perfilAtor, perfilAlvo) {
+		respostas.Erro(w, http.StatusForbidden, errors.New("não é possivel agir sobre uma conta de perfil igual ou superior ao seu"))
+		return false
+	}
+
+	return true
+}
+
+// exigirModerador verifica se o usuário é moderador ou administrador, respondendo 403 caso não seja
+func exigirModerador(w http.ResponseWriter, db *sql.DB, usuarioID uint64) bool {
+	repositorio := repositorios.NovoRepositorioDeUsuarios(db)
+	perfil, erro := repositorio.BuscarPerfil(usuarioID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return false
+	}
+
+	if perfil != modelos.PerfilModerador && perfil != modelos.PerfilAdministrador {
+		respostas.Erro(w, http.StatusForbidden, errors.New("acesso restrito a moderadores"))
+		return false
+	}
+
+	return true
+}
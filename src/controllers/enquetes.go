@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"api/src/autenticacao"
+	"api/src/banco"
+	"api/src/repositorios"
+	"api/src/respostas"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// VotarEnquete registra o voto do usuário logado na enquete de uma publicação e
+// retorna a publicação com os resultados que ele pode ver
+func VotarEnquete(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	publicacaoId, erro := strconv.ParseUint(parametros["publicacaoId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	corpoRequisicao, erro := ioutil.ReadAll(r.Body)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnprocessableEntity, erro)
+		return
+	}
+
+	var voto struct {
+		Opcoes []uint64 `json:"opcoes"`
+	}
+	if erro = json.Unmarshal(corpoRequisicao, &voto); erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	repositorioPublicacoes := repositorios.NovoRepositorioDePublicacoes(db)
+	podeVer, erro := repositorioPublicacoes.PodeVer(publicacaoId, usuarioID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if !podeVer {
+		respostas.Erro(w, http.StatusNotFound, errors.New("publicação não encontrada"))
+		return
+	}
+
+	repositorio := repositorios.NovoRepositorioDeEnquetes(db)
+	enquete, erro := repositorio.BuscarEnquete(publicacaoId)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if len(enquete.Opcoes) == 0 {
+		respostas.Erro(w, http.StatusNotFound, errors.New("a publicação não tem enquete"))
+		return
+	}
+
+	if enquete.Encerrada {
+		respostas.Erro(w, http.StatusForbidden, errors.New("a enquete já foi encerrada"))
+		return
+	}
+
+	if len(voto.Opcoes) == 0 {
+		respostas.Erro(w, http.StatusBadRequest, errors.New("escolha ao menos uma opção"))
+		return
+	}
+
+	if !enquete.Multipla && len(voto.Opcoes) > 1 {
+		respostas.Erro(w, http.StatusBadRequest, errors.New("a enquete permite apenas uma opção"))
+		return
+	}
+
+	validas := make(map[uint64]bool, len(enquete.Opcoes))
+	for _, opcao := range enquete.Opcoes {
+		validas[opcao.ID] = true
+	}
+
+	escolhidas := make(map[uint64]bool, len(voto.Opcoes))
+	for _, opcaoID := range voto.Opcoes {
+		if !validas[opcaoID] || escolhidas[opcaoID] {
+			respostas.Erro(w, http.StatusBadRequest, errors.New("opção inválida"))
+			return
+		}
+		escolhidas[opcaoID] = true
+	}
+
+	votou, erro := repositorio.Votar(publicacaoId, usuarioID, voto.Opcoes)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if !votou {
+		respostas.Erro(w, http.StatusConflict, errors.New("você já votou nesta enquete"))
+		return
+	}
+
+	publicacao, erro := repositorioPublicacoes.BuscarPublicacaoParaLeitor(publicacaoId, usuarioID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusOK, publicacao)
+}
@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"api/src/autenticacao"
+	"api/src/banco"
+	"api/src/modelos"
+	"api/src/repositorios"
+	"api/src/respostas"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Limites do período das estatísticas, em dias
+const (
+	diasPadraoEstatisticas = 30
+	diasMaximoEstatisticas = 366
+)
+
+// BuscarEstatisticas retorna o alcance das publicações do usuário logado entre de e ate
+// (AAAA-MM-DD, inclusive). Sem datas, o período são os últimos 30 dias
+func BuscarEstatisticas(w http.ResponseWriter, r *http.Request) {
+	parametros := mux.Vars(r)
+
+	usuarioId, erro := strconv.ParseUint(parametros["usuarioId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	usuarioNoToken, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	if usuarioId != usuarioNoToken {
+		respostas.Erro(w, http.StatusForbidden, errors.New("Não é possivel ver as estatísticas de um usuário diferente do usuário logado"))
+		return
+	}
+
+	de, ate, erro := extrairPeriodoEstatisticas(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	repositorio := repositorios.NovoRepositorioDeEstatisticas(db)
+	estatisticasDoUsuario, erro := repositorio.Buscar(usuarioId, de.Format(modelos.FormatoDia), ate.Format(modelos.FormatoDia))
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusOK, estatisticasDoUsuario)
+}
+
+func extrairPeriodoEstatisticas(r *http.Request) (time.Time, time.Time, error) {
+	parametros := r.URL.Query()
+
+	ate, erro := time.ParseInLocation(modelos.FormatoDia, time.Now().Format(modelos.FormatoDia), time.Local)
+	if erro != nil {
+		return time.Time{}, time.Time{}, erro
+	}
+
+	if valor := parametros.Get("ate"); valor != "" {
+		if ate, erro = time.ParseInLocation(modelos.FormatoDia, valor, time.Local); erro != nil {
+			return time.Time{}, time.Time{}, errors.New("data final inválida, use o formato AAAA-MM-DD")
+		}
+	}
+
+	de := ate.AddDate(0, 0, -(diasPadraoEstatisticas - 1))
+	if valor := parametros.Get("de"); valor != "" {
+		if de, erro = time.ParseInLocation(modelos.FormatoDia, valor, time.Local); erro != nil {
+			return time.Time{}, time.Time{}, errors.New("data inicial inválida, use o formato AAAA-MM-DD")
+		}
+	}
+
+	if de.After(ate) {
+		return time.Time{}, time.Time{}, errors.New("a data inicial não pode ser posterior à data final")
+	}
+
+	if ate.Sub(de) >= diasMaximoEstatisticas*24*time.Hour {
+		return time.Time{}, time.Time{}, errors.New("o período das estatísticas pode ter no máximo 366 dias")
+	}
+
+	return de, ate, nil
+}
@@ -0,0 +1,209 @@
+package controllers
+
+import (
+	"api/src/autenticacao"
+	"api/src/config"
+	"api/src/eventos"
+	"api/src/respostas"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	intervaloHeartbeat = 30 * time.Second
+	prazoEscrita       = 10 * time.Second
+	// validadeTokenEventos é o prazo para abrir a conexão com o token de eventos; a conexão
+	// aberta continua valendo depois dele
+	validadeTokenEventos = time.Minute
+)
+
+var upgrader = websocket.Upgrader{
+	CheckOrigin: origemPermitida,
+}
+
+// origemPermitida aceita conexões sem Origin (clientes fora do navegador), da própria API
+// ou das origens configuradas em ORIGENS_PERMITIDAS
+func origemPermitida(r *http.Request) bool {
+	origem := r.Header.Get("Origin")
+	if origem == "" {
+		return true
+	}
+
+	if endereco, erro := url.Parse(origem); erro == nil && strings.EqualFold(endereco.Host, r.Host) {
+		return true
+	}
+
+	for _, permitida := range config.OrigensPermitidas {
+		if strings.EqualFold(strings.TrimSuffix(permitida, "/"), origem) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CriarTokenDeEventos gera um token de curta duração para abrir /eventos pelo navegador, onde
+// EventSource e WebSocket não enviam o cabeçalho Authorization. Ele vai no parâmetro token da URL
+func CriarTokenDeEventos(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	token, erro := autenticacao.CriarTokenDeEscopo(usuarioID, autenticacao.EscopoEventos, validadeTokenEventos)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusCreated, struct {
+		Token    string    `json:"token"`
+		ExpiraEm time.Time `json:"expiraEm"`
+	}{token, time.Now().Add(validadeTokenEventos)})
+}
+
+// ReceberEventos mantém uma conexão aberta enviando os eventos do usuário logado,
+// via WebSocket quando solicitado o upgrade ou via Server-Sent Events. No navegador, o
+// token vem de CriarTokenDeEventos e é passado no parâmetro token
+func ReceberEventos(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	if websocket.IsWebSocketUpgrade(r) {
+		receberEventosWebSocket(w, r, usuarioID)
+		return
+	}
+
+	receberEventosSSE(w, r, usuarioID)
+}
+
+func receberEventosSSE(w http.ResponseWriter, r *http.Request, usuarioID uint64) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		respostas.Erro(w, http.StatusInternalServerError, errors.New("streaming não suportado"))
+		return
+	}
+
+	// Um EventSource novo (por exemplo, aberto com outro token) não envia Last-Event-ID,
+	// então o último evento recebido também pode vir no parâmetro ultimoId
+	ultimoEvento := r.Header.Get("Last-Event-ID")
+	if ultimoEvento == "" {
+		ultimoEvento = r.URL.Query().Get("ultimoId")
+	}
+
+	ultimoID, _ := strconv.ParseUint(ultimoEvento, 10, 64)
+	assinatura, pendentes := eventos.Padrao.Assinar(usuarioID, ultimoID)
+	defer eventos.Padrao.Cancelar(assinatura)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for _, evento := range pendentes {
+		if erro := escreverEventoSSE(w, evento); erro != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(intervaloHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case evento, aberto := <-assinatura.Eventos:
+			if !aberto {
+				return
+			}
+
+			if erro := escreverEventoSSE(w, evento); erro != nil {
+				return
+			}
+			flusher.Flush()
+		case <-heartbeat.C:
+			if _, erro := fmt.Fprint(w, ": heartbeat\n\n"); erro != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func escreverEventoSSE(w http.ResponseWriter, evento eventos.Evento) error {
+	dados, erro := json.Marshal(evento.Dados)
+	if erro != nil {
+		return erro
+	}
+
+	_, erro = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", evento.ID, evento.Tipo, dados)
+	return erro
+}
+
+func receberEventosWebSocket(w http.ResponseWriter, r *http.Request, usuarioID uint64) {
+	conexao, erro := upgrader.Upgrade(w, r, nil)
+	if erro != nil {
+		return
+	}
+
+	defer conexao.Close()
+
+	assinatura, _ := eventos.Padrao.Assinar(usuarioID, 0)
+	defer eventos.Padrao.Cancelar(assinatura)
+
+	// As mensagens do cliente são descartadas; a leitura só detecta o fechamento e os pongs
+	encerrada := make(chan struct{})
+	conexao.SetReadDeadline(time.Now().Add(2 * intervaloHeartbeat))
+	conexao.SetPongHandler(func(string) error {
+		return conexao.SetReadDeadline(time.Now().Add(2 * intervaloHeartbeat))
+	})
+	go func() {
+		defer close(encerrada)
+		for {
+			if _, _, erro := conexao.ReadMessage(); erro != nil {
+				return
+			}
+		}
+	}()
+
+	heartbeat := time.NewTicker(intervaloHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-encerrada:
+			return
+		case evento, aberto := <-assinatura.Eventos:
+			if !aberto {
+				conexao.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "fila de eventos cheia"),
+					time.Now().Add(prazoEscrita))
+				return
+			}
+
+			conexao.SetWriteDeadline(time.Now().Add(prazoEscrita))
+			if erro := conexao.WriteJSON(evento); erro != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if erro := conexao.WriteControl(websocket.PingMessage, nil, time.Now().Add(prazoEscrita)); erro != nil {
+				return
+			}
+		}
+	}
+}
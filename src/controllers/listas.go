@@ -0,0 +1,518 @@
+package controllers
+
+import (
+	"api/src/autenticacao"
+	"api/src/banco"
+	"api/src/estatisticas"
+	"api/src/modelos"
+	"api/src/repositorios"
+	"api/src/respostas"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// CriarLista cria uma lista do usuário logado
+func CriarLista(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	corpoRequisicao, erro := ioutil.ReadAll(r.Body)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnprocessableEntity, erro)
+		return
+	}
+
+	var lista modelos.Lista
+	if erro = json.Unmarshal(corpoRequisicao, &lista); erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	if erro = lista.Preparar(); erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	repositorio := repositorios.NovoRepositorioDeListas(db)
+	listaID, erro := repositorio.Criar(modelos.Lista{
+		Nome:      lista.Nome,
+		Descricao: lista.Descricao,
+		Privada:   lista.Privada,
+		DonoID:    usuarioID,
+	})
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	lista, erro = repositorio.BuscarPorID(listaID, usuarioID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusCreated, lista)
+}
+
+// BuscarMinhasListas retorna as listas criadas pelo usuário logado
+func BuscarMinhasListas(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	repositorio := repositorios.NovoRepositorioDeListas(db)
+	listas, erro := repositorio.BuscarListasDoUsuario(usuarioID, usuarioID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusOK, listas)
+}
+
+// BuscarListasSeguidas retorna as listas de outros usuários que o usuário logado segue
+func BuscarListasSeguidas(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	repositorio := repositorios.NovoRepositorioDeListas(db)
+	listas, erro := repositorio.BuscarListasSeguidas(usuarioID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusOK, listas)
+}
+
+// BuscarListasDoUsuario retorna as listas públicas criadas por um usuário
+func BuscarListasDoUsuario(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	donoId, erro := strconv.ParseUint(parametros["usuarioId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	repositorio := repositorios.NovoRepositorioDeListas(db)
+	listas, erro := repositorio.BuscarListasDoUsuario(donoId, usuarioID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusOK, listas)
+}
+
+// BuscarLista retorna uma lista
+func BuscarLista(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	lista, ok := carregarLista(w, r, db, usuarioID)
+	if !ok {
+		return
+	}
+
+	respostas.JSON(w, http.StatusOK, lista)
+}
+
+// AtualizarLista altera o nome, a descrição e a privacidade de uma lista do usuário logado
+func AtualizarLista(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	corpoRequisicao, erro := ioutil.ReadAll(r.Body)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnprocessableEntity, erro)
+		return
+	}
+
+	var dados modelos.Lista
+	if erro = json.Unmarshal(corpoRequisicao, &dados); erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	if erro = dados.Preparar(); erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	lista, ok := carregarLista(w, r, db, usuarioID)
+	if !ok || !exigirDonoDaLista(w, lista, usuarioID) {
+		return
+	}
+
+	repositorio := repositorios.NovoRepositorioDeListas(db)
+	if erro = repositorio.Atualizar(lista.ID, dados); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+// DeletarLista apaga uma lista do usuário logado
+func DeletarLista(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	lista, ok := carregarLista(w, r, db, usuarioID)
+	if !ok || !exigirDonoDaLista(w, lista, usuarioID) {
+		return
+	}
+
+	repositorio := repositorios.NovoRepositorioDeListas(db)
+	if erro = repositorio.Deletar(lista.ID); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+// BuscarMembrosDaLista retorna os usuários de uma lista
+func BuscarMembrosDaLista(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	limite, deslocamento := extrairPaginacao(r)
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	lista, ok := carregarLista(w, r, db, usuarioID)
+	if !ok {
+		return
+	}
+
+	repositorio := repositorios.NovoRepositorioDeListas(db)
+	membros, erro := repositorio.BuscarMembros(lista.ID, limite, deslocamento)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusOK, membros)
+}
+
+// AdicionarMembroNaLista inclui um usuário em uma lista do usuário logado.
+// Não é preciso seguir o usuário para adicioná-lo
+func AdicionarMembroNaLista(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	membroId, erro := strconv.ParseUint(parametros["usuarioId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	lista, ok := carregarLista(w, r, db, usuarioID)
+	if !ok || !exigirDonoDaLista(w, lista, usuarioID) {
+		return
+	}
+
+	repositorioUsuarios := repositorios.NovoRepositorioDeUsuarios(db)
+	membro, erro := repositorioUsuarios.BuscarUsuarioPorID(membroId)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	bloqueado, erro := repositorioUsuarios.ExisteBloqueio(usuarioID, membroId)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if membro.ID == 0 || bloqueado {
+		respostas.Erro(w, http.StatusNotFound, errors.New("usuário não encontrado"))
+		return
+	}
+
+	repositorio := repositorios.NovoRepositorioDeListas(db)
+	if erro = repositorio.AdicionarMembro(lista.ID, membroId); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+// RemoverMembroDaLista tira um usuário de uma lista do usuário logado
+func RemoverMembroDaLista(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	membroId, erro := strconv.ParseUint(parametros["usuarioId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	lista, ok := carregarLista(w, r, db, usuarioID)
+	if !ok || !exigirDonoDaLista(w, lista, usuarioID) {
+		return
+	}
+
+	repositorio := repositorios.NovoRepositorioDeListas(db)
+	if erro = repositorio.RemoverMembro(lista.ID, membroId); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+// BuscarPublicacoesDaLista retorna o feed dos membros de uma lista
+func BuscarPublicacoesDaLista(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	lista, ok := carregarLista(w, r, db, usuarioID)
+	if !ok {
+		return
+	}
+
+	repositorio := repositorios.NovoRepositorioDePublicacoes(db)
+	publicacoes, erro := repositorio.BuscarPublicacoesDaLista(lista.ID, usuarioID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	estatisticas.Padrao.RegistrarImpressoes(usuarioID, publicacoes)
+	respostas.JSON(w, http.StatusOK, publicacoes)
+}
+
+// SeguirLista faz o usuário logado seguir a lista pública de outro usuário
+func SeguirLista(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	lista, ok := carregarLista(w, r, db, usuarioID)
+	if !ok {
+		return
+	}
+
+	if lista.DonoID == usuarioID {
+		respostas.Erro(w, http.StatusBadRequest, errors.New("não é possível seguir a sua própria lista"))
+		return
+	}
+
+	repositorio := repositorios.NovoRepositorioDeListas(db)
+	if erro = repositorio.Seguir(lista.ID, usuarioID); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+// DeixarDeSeguirLista faz o usuário logado parar de seguir uma lista
+func DeixarDeSeguirLista(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	listaId, erro := strconv.ParseUint(parametros["listaId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	repositorio := repositorios.NovoRepositorioDeListas(db)
+	if erro = repositorio.DeixarDeSeguir(listaId, usuarioID); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+// carregarLista busca a lista da rota. Listas privadas são tratadas como inexistentes para
+// quem não é o dono. Em caso de falha, a resposta já foi escrita
+func carregarLista(w http.ResponseWriter, r *http.Request, db *sql.DB, usuarioID uint64) (modelos.Lista, bool) {
+	parametros := mux.Vars(r)
+	listaId, erro := strconv.ParseUint(parametros["listaId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return modelos.Lista{}, false
+	}
+
+	repositorio := repositorios.NovoRepositorioDeListas(db)
+	lista, erro := repositorio.BuscarPorID(listaId, usuarioID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return modelos.Lista{}, false
+	}
+
+	if lista.ID == 0 {
+		respostas.Erro(w, http.StatusNotFound, errors.New("lista não encontrada"))
+		return modelos.Lista{}, false
+	}
+
+	return lista, true
+}
+
+// exigirDonoDaLista verifica se o usuário é o dono da lista, respondendo 403 caso não seja
+func exigirDonoDaLista(w http.ResponseWriter, lista modelos.Lista, usuarioID uint64) bool {
+	if lista.DonoID != usuarioID {
+		respostas.Erro(w, http.StatusForbidden, errors.New("apenas o dono pode alterar a lista"))
+		return false
+	}
+
+	return true
+}
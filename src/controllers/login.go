@@ -46,6 +46,17 @@ func Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	estado, erro := repositorio.BuscarEstado(usuarioSalvoNoBanco.ID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if erro = estado.PermiteAcesso(); erro != nil {
+		respostas.Erro(w, http.StatusForbidden, erro)
+		return
+	}
+
 	token, erro := autenticacao.CriarToken(usuarioSalvoNoBanco.ID)
 	if erro != nil {
 		respostas.Erro(w, http.StatusInternalServerError, erro)
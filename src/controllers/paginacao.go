@@ -0,0 +1,30 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+)
+
+const (
+	limitePadrao = 20
+	limiteMaximo = 100
+)
+
+// extrairPaginacao lê os parâmetros pagina e limite da query string e devolve limite e deslocamento
+func extrairPaginacao(r *http.Request) (uint64, uint64) {
+	pagina, erro := strconv.ParseUint(r.URL.Query().Get("pagina"), 10, 64)
+	if erro != nil || pagina == 0 {
+		pagina = 1
+	}
+
+	limite, erro := strconv.ParseUint(r.URL.Query().Get("limite"), 10, 64)
+	if erro != nil || limite == 0 {
+		limite = limitePadrao
+	}
+
+	if limite > limiteMaximo {
+		limite = limiteMaximo
+	}
+
+	return limite, (pagina - 1) * limite
+}
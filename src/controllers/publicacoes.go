@@ -3,11 +3,16 @@ package controllers
 import (
 	"api/src/autenticacao"
 	"api/src/banco"
+	"api/src/config"
+	"api/src/divulgacao"
+	"api/src/estatisticas"
+	"api/src/eventos"
 	"api/src/modelos"
 	"api/src/repositorios"
 	"api/src/respostas"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"strconv"
@@ -39,11 +44,48 @@ func CriarPublicacao(w http.ResponseWriter, r *http.Request) {
 
 	publicacao.AutorID = usuarioID
 
+	if publicacao.Visibilidade == "" {
+		repositorioUsuarios := repositorios.NovoRepositorioDeUsuarios(db)
+		publicacao.Visibilidade, erro = repositorioUsuarios.BuscarVisibilidadePadrao(usuarioID)
+		if erro != nil {
+			respostas.Erro(w, http.StatusInternalServerError, erro)
+			return
+		}
+	}
+
 	if erro = publicacao.Preparar(); erro != nil {
 		respostas.Erro(w, http.StatusBadRequest, erro)
 		return
 	}
 
+	if publicacao.CitacaoID != 0 {
+		repositorioCitacoes := repositorios.NovoRepositorioDePublicacoes(db)
+		publica, erro := repositorioCitacoes.PodeVer(publicacao.CitacaoID, 0)
+		if erro != nil {
+			respostas.Erro(w, http.StatusInternalServerError, erro)
+			return
+		}
+
+		if !publica {
+			respostas.Erro(w, http.StatusBadRequest, errors.New("apenas publicações públicas podem ser citadas"))
+			return
+		}
+	}
+
+	if publicacao.ComunidadeID != 0 {
+		repositorioComunidades := repositorios.NovoRepositorioDeComunidades(db)
+		papel, erro := repositorioComunidades.BuscarPapel(publicacao.ComunidadeID, usuarioID)
+		if erro != nil {
+			respostas.Erro(w, http.StatusInternalServerError, erro)
+			return
+		}
+
+		if papel == "" {
+			respostas.Erro(w, http.StatusForbidden, errors.New("apenas membros podem publicar na comunidade"))
+			return
+		}
+	}
+
 	repositorio := repositorios.NovoRepositorioDePublicacoes(db)
 	publicacao.ID, erro = repositorio.Criar(publicacao)
 
@@ -52,6 +94,46 @@ func CriarPublicacao(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	repositorioTags := repositorios.NovoRepositorioDeTags(db)
+	if erro = repositorioTags.SalvarTagsDaPublicacao(publicacao.ID, publicacao.Tags); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	repositorioMencoes := repositorios.NovoRepositorioDeMencoes(db)
+	publicacao.Mencoes, erro = repositorioMencoes.Resolver(usuarioID, publicacao.Mencoes)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if erro = repositorioMencoes.SalvarMencoesDaPublicacao(publicacao.ID, publicacao.Mencoes); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if publicacao.Enquete != nil {
+		repositorioEnquetes := repositorios.NovoRepositorioDeEnquetes(db)
+		if erro = repositorioEnquetes.Criar(publicacao.ID, *publicacao.Enquete); erro != nil {
+			respostas.Erro(w, http.StatusInternalServerError, erro)
+			return
+		}
+
+		enquete, erro := repositorioEnquetes.BuscarEnquete(publicacao.ID)
+		if erro != nil {
+			respostas.Erro(w, http.StatusInternalServerError, erro)
+			return
+		}
+		publicacao.Enquete = &enquete
+	}
+
+	if publicacao.Status == modelos.StatusPublicada {
+		if erro = divulgacao.Publicacao(db, publicacao); erro != nil {
+			respostas.Erro(w, http.StatusInternalServerError, erro)
+			return
+		}
+	}
+
 	respostas.JSON(w, http.StatusCreated, publicacao)
 }
 
@@ -75,10 +157,17 @@ func BuscarPublicacoes(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	estatisticas.Padrao.RegistrarImpressoes(usuarioID, publicacoes)
 	respostas.JSON(w, http.StatusOK, publicacoes)
 }
 
 func BuscarPublicacao(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
 	parametros := mux.Vars(r)
 
 	publicacaoId, erro := strconv.ParseUint(parametros["publicacaoId"], 10, 64)
@@ -96,12 +185,18 @@ func BuscarPublicacao(w http.ResponseWriter, r *http.Request) {
 	defer db.Close()
 
 	repositorio := repositorios.NovoRepositorioDePublicacoes(db)
-	publicacao, erro := repositorio.BuscarPublicacao(publicacaoId)
+	publicacao, erro := repositorio.BuscarPublicacaoParaLeitor(publicacaoId, usuarioID)
 	if erro != nil {
 		respostas.Erro(w, http.StatusInternalServerError, erro)
 		return
 	}
 
+	if publicacao.ID == 0 {
+		respostas.Erro(w, http.StatusNotFound, errors.New("publicação não encontrada"))
+		return
+	}
+
+	estatisticas.Padrao.RegistrarVisualizacao(usuarioID, publicacao)
 	respostas.JSON(w, http.StatusOK, publicacao)
 }
 
@@ -133,7 +228,13 @@ func AtualizarPublicacao(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if publicacaoSalvaBanco.AutorID != usuarioID {
+	podeModerar, erro := podeModerarPublicacao(db, usuarioID, publicacaoSalvaBanco)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if !podeModerar {
 		respostas.Erro(w, http.StatusForbidden, errors.New("não é possível atualizar uma publicação de outro autor"))
 		return
 	}
@@ -155,6 +256,48 @@ func AtualizarPublicacao(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	repositorioTags := repositorios.NovoRepositorioDeTags(db)
+	if erro = repositorioTags.SalvarTagsDaPublicacao(publicacaoId, publicacao.Tags); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	repositorioMencoes := repositorios.NovoRepositorioDeMencoes(db)
+	// Quem edita pode ser um moderador, mas os bloqueios que valem são os do autor
+	publicacao.Mencoes, erro = repositorioMencoes.Resolver(publicacaoSalvaBanco.AutorID, publicacao.Mencoes)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	mencionadosAntes, erro := repositorioMencoes.BuscarMencionados(publicacaoId)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if erro = repositorioMencoes.SalvarMencoesDaPublicacao(publicacaoId, publicacao.Mencoes); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	// Rascunhos e agendadas notificam todas as menções quando forem divulgadas; publicações já
+	// divulgadas notificam só quem passou a ser mencionado nesta edição
+	if publicacaoSalvaBanco.Status == modelos.StatusPublicada {
+		var novasMencoes []modelos.Mencao
+		for _, mencao := range publicacao.Mencoes {
+			if !mencionadosAntes[mencao.UsuarioID] {
+				novasMencoes = append(novasMencoes, mencao)
+				mencionadosAntes[mencao.UsuarioID] = true
+			}
+		}
+
+		if erro = divulgacao.NotificarMencoes(db, publicacaoSalvaBanco, novasMencoes); erro != nil {
+			respostas.Erro(w, http.StatusInternalServerError, erro)
+			return
+		}
+	}
+
 	respostas.JSON(w, http.StatusNoContent, nil)
 
 }
@@ -187,8 +330,14 @@ func DeletarPublicacao(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if publicacaoSalvaBanco.AutorID != usuarioID {
-		respostas.Erro(w, http.StatusForbidden, errors.New("não é possível atualizar uma publicação de outro autor"))
+	podeModerar, erro := podeModerarPublicacao(db, usuarioID, publicacaoSalvaBanco)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if !podeModerar {
+		respostas.Erro(w, http.StatusForbidden, errors.New("não é possível deletar uma publicação de outro autor"))
 		return
 	}
 
@@ -201,7 +350,97 @@ func DeletarPublicacao(w http.ResponseWriter, r *http.Request) {
 
 }
 
+// BuscarRevisoes retorna as versões de uma publicação com as diferenças entre cada edição
+func BuscarRevisoes(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	publicacaoId, erro := strconv.ParseUint(parametros["publicacaoId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	repositorio := repositorios.NovoRepositorioDePublicacoes(db)
+	podeVer, erro := repositorio.PodeVer(publicacaoId, usuarioID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if !podeVer {
+		respostas.Erro(w, http.StatusNotFound, errors.New("publicação não encontrada"))
+		return
+	}
+
+	revisoes, erro := repositorio.BuscarRevisoes(publicacaoId)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	modelos.CompararRevisoes(revisoes)
+
+	respostas.JSON(w, http.StatusOK, revisoes)
+}
+
+// RestaurarPublicacao desfaz a exclusão de uma publicação do usuário logado dentro do prazo configurado
+func RestaurarPublicacao(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	publicacaoId, erro := strconv.ParseUint(parametros["publicacaoId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	repositorio := repositorios.NovoRepositorioDePublicacoes(db)
+	restaurada, erro := repositorio.Restaurar(publicacaoId, usuarioID, config.PrazoRestauracaoPublicacao)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if !restaurada {
+		respostas.Erro(w, http.StatusNotFound, errors.New("nenhuma publicação excluída dentro do prazo de restauração"))
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
 func BucarPublicacoesPorUsuario(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
 	parametros := mux.Vars(r)
 
 	usuarioId, erro := strconv.ParseUint(parametros["usuarioId"], 10, 64)
@@ -219,16 +458,23 @@ func BucarPublicacoesPorUsuario(w http.ResponseWriter, r *http.Request) {
 	defer db.Close()
 
 	repositorio := repositorios.NovoRepositorioDePublicacoes(db)
-	publicacoes, erro := repositorio.BuscarPublicacaoPorUsuario(usuarioId)
+	publicacoes, erro := repositorio.BuscarPublicacaoPorUsuario(usuarioId, usuarioID)
 	if erro != nil {
 		respostas.Erro(w, http.StatusInternalServerError, erro)
 		return
 	}
 
+	estatisticas.Padrao.RegistrarImpressoes(usuarioID, publicacoes)
 	respostas.JSON(w, http.StatusOK, publicacoes)
 }
 
 func CurtirPublicacao(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
 	parametros := mux.Vars(r)
 
 	publicacaoId, erro := strconv.ParseUint(parametros["publicacaoId"], 10, 64)
@@ -246,7 +492,40 @@ func CurtirPublicacao(w http.ResponseWriter, r *http.Request) {
 	defer db.Close()
 
 	repositorio := repositorios.NovoRepositorioDePublicacoes(db)
-	if erro = repositorio.CurtirPublicacao(publicacaoId); erro != nil {
+	podeVer, erro := repositorio.PodeVer(publicacaoId, usuarioID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	publicacao, erro := repositorio.BuscarPublicacao(publicacaoId)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if !podeVer || publicacao.ID == 0 {
+		respostas.Erro(w, http.StatusNotFound, errors.New("publicação não encontrada"))
+		return
+	}
+
+	curtida, erro := repositorio.CurtirPublicacao(publicacaoId, usuarioID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if !curtida {
+		respostas.JSON(w, http.StatusNoContent, nil)
+		return
+	}
+
+	if erro = divulgacao.Notificar(db, publicacao.AutorID, usuarioID, modelos.NotificacaoCurtida, publicacaoId); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if erro = publicarCurtidas(repositorio, publicacaoId); erro != nil {
 		respostas.Erro(w, http.StatusInternalServerError, erro)
 		return
 	}
@@ -256,6 +535,12 @@ func CurtirPublicacao(w http.ResponseWriter, r *http.Request) {
 }
 
 func DescurtirPublicacao(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
 	parametros := mux.Vars(r)
 
 	publicacaoId, erro := strconv.ParseUint(parametros["publicacaoId"], 10, 64)
@@ -273,10 +558,139 @@ func DescurtirPublicacao(w http.ResponseWriter, r *http.Request) {
 	defer db.Close()
 
 	repositorio := repositorios.NovoRepositorioDePublicacoes(db)
-	if erro = repositorio.DescurtirPublicacao(publicacaoId); erro != nil {
+	descurtida, erro := repositorio.DescurtirPublicacao(publicacaoId, usuarioID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if !descurtida {
+		respostas.JSON(w, http.StatusNoContent, nil)
+		return
+	}
+
+	if erro = publicarCurtidas(repositorio, publicacaoId); erro != nil {
 		respostas.Erro(w, http.StatusInternalServerError, erro)
 		return
 	}
 
 	respostas.JSON(w, http.StatusNoContent, nil)
 }
+
+// FixarPublicacao destaca uma publicação do usuário logado no topo do seu perfil
+func FixarPublicacao(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	publicacaoId, erro := strconv.ParseUint(parametros["publicacaoId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	repositorio := repositorios.NovoRepositorioDePublicacoes(db)
+	publicacaoSalvaBanco, erro := repositorio.BuscarPublicacao(publicacaoId)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if publicacaoSalvaBanco.AutorID != usuarioID {
+		respostas.Erro(w, http.StatusForbidden, errors.New("não é possível fixar uma publicação de outro autor"))
+		return
+	}
+
+	if publicacaoSalvaBanco.Status != modelos.StatusPublicada {
+		respostas.Erro(w, http.StatusBadRequest, errors.New("apenas publicações publicadas podem ser fixadas"))
+		return
+	}
+
+	fixada, erro := repositorio.Fixar(publicacaoId, usuarioID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if !fixada {
+		respostas.Erro(w, http.StatusConflict,
+			fmt.Errorf("só é possivel fixar até %d publicações", modelos.MaximoPublicacoesFixadas))
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+// DesfixarPublicacao remove o destaque de uma publicação do usuário logado
+func DesfixarPublicacao(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	publicacaoId, erro := strconv.ParseUint(parametros["publicacaoId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	repositorio := repositorios.NovoRepositorioDePublicacoes(db)
+	publicacaoSalvaBanco, erro := repositorio.BuscarPublicacao(publicacaoId)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if publicacaoSalvaBanco.AutorID != usuarioID {
+		respostas.Erro(w, http.StatusForbidden, errors.New("não é possível desfixar uma publicação de outro autor"))
+		return
+	}
+
+	if erro = repositorio.Desfixar(publicacaoId); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+// publicarCurtidas envia em tempo real o total de curtidas de uma publicação para quem a vê no feed
+func publicarCurtidas(repositorio *repositorios.RepositorioPublicacoes, publicacaoID uint64) error {
+	publicacao, erro := repositorio.BuscarPublicacao(publicacaoID)
+	if erro != nil {
+		return erro
+	}
+
+	audiencia, erro := repositorio.BuscarAudiencia(publicacao)
+	if erro != nil {
+		return erro
+	}
+
+	eventos.Publicar(audiencia, eventos.EventoCurtidas, struct {
+		PublicacaoID uint64 `json:"publicacaoId"`
+		Curtidas     uint64 `json:"curtidas"`
+	}{publicacao.ID, publicacao.Curtidas})
+
+	return nil
+}
@@ -0,0 +1,208 @@
+package controllers
+
+import (
+	"api/src/autenticacao"
+	"api/src/banco"
+	"api/src/config"
+	"api/src/eventos"
+	"api/src/repositorios"
+	"api/src/respostas"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// ReagirPublicacao registra ou troca a reação do usuário logado a uma publicação
+func ReagirPublicacao(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	publicacaoId, erro := strconv.ParseUint(parametros["publicacaoId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	corpoRequisicao, erro := ioutil.ReadAll(r.Body)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnprocessableEntity, erro)
+		return
+	}
+
+	var reacao struct {
+		Tipo string `json:"tipo"`
+	}
+	if erro = json.Unmarshal(corpoRequisicao, &reacao); erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	if !reacaoValida(reacao.Tipo) {
+		respostas.Erro(w, http.StatusBadRequest, errors.New("tipo de reação inválido"))
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	repositorioPublicacoes := repositorios.NovoRepositorioDePublicacoes(db)
+	podeVer, erro := repositorioPublicacoes.PodeVer(publicacaoId, usuarioID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if !podeVer {
+		respostas.Erro(w, http.StatusNotFound, errors.New("publicação não encontrada"))
+		return
+	}
+
+	repositorio := repositorios.NovoRepositorioDeReacoes(db)
+	if erro = repositorio.Reagir(publicacaoId, usuarioID, reacao.Tipo); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if erro = publicarReacoes(repositorioPublicacoes, publicacaoId); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+// RemoverReacao desfaz a reação do usuário logado a uma publicação
+func RemoverReacao(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	publicacaoId, erro := strconv.ParseUint(parametros["publicacaoId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	repositorio := repositorios.NovoRepositorioDeReacoes(db)
+	if erro = repositorio.RemoverReacao(publicacaoId, usuarioID); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if erro = publicarReacoes(repositorios.NovoRepositorioDePublicacoes(db), publicacaoId); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+// BuscarReacoes lista quem reagiu a uma publicação, podendo filtrar por tipo de reação
+func BuscarReacoes(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	publicacaoId, erro := strconv.ParseUint(parametros["publicacaoId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	tipo := r.URL.Query().Get("tipo")
+	if tipo != "" && !reacaoValida(tipo) {
+		respostas.Erro(w, http.StatusBadRequest, errors.New("tipo de reação inválido"))
+		return
+	}
+
+	limite, deslocamento := extrairPaginacao(r)
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	podeVer, erro := repositorios.NovoRepositorioDePublicacoes(db).PodeVer(publicacaoId, usuarioID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if !podeVer {
+		respostas.Erro(w, http.StatusNotFound, errors.New("publicação não encontrada"))
+		return
+	}
+
+	repositorio := repositorios.NovoRepositorioDeReacoes(db)
+	reacoes, erro := repositorio.BuscarReacoes(publicacaoId, usuarioID, tipo, limite, deslocamento)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusOK, reacoes)
+}
+
+// reacaoValida indica se o tipo é uma das reações configuradas
+func reacaoValida(tipo string) bool {
+	for _, reacao := range config.Reacoes {
+		if reacao == tipo {
+			return true
+		}
+	}
+
+	return false
+}
+
+func publicarReacoes(repositorio *repositorios.RepositorioPublicacoes, publicacaoID uint64) error {
+	publicacao, erro := repositorio.BuscarPublicacao(publicacaoID)
+	if erro != nil {
+		return erro
+	}
+
+	if publicacao.ID == 0 {
+		return nil
+	}
+
+	audiencia, erro := repositorio.BuscarAudiencia(publicacao)
+	if erro != nil {
+		return erro
+	}
+
+	eventos.Publicar(audiencia, eventos.EventoReacoes, struct {
+		PublicacaoID uint64            `json:"publicacaoId"`
+		Reacoes      map[string]uint64 `json:"reacoes"`
+	}{publicacao.ID, publicacao.Reacoes})
+
+	return nil
+}
@@ -0,0 +1,148 @@
+package controllers
+
+import (
+	"api/src/autenticacao"
+	"api/src/banco"
+	"api/src/divulgacao"
+	"api/src/eventos"
+	"api/src/modelos"
+	"api/src/repositorios"
+	"api/src/respostas"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// RepostarPublicacao compartilha uma publicação pública no feed dos seguidores do usuário logado
+func RepostarPublicacao(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	publicacaoId, erro := strconv.ParseUint(parametros["publicacaoId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	repositorioPublicacoes := repositorios.NovoRepositorioDePublicacoes(db)
+	podeVer, erro := repositorioPublicacoes.PodeVer(publicacaoId, usuarioID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	publicacao, erro := repositorioPublicacoes.BuscarPublicacao(publicacaoId)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if !podeVer || publicacao.ID == 0 {
+		respostas.Erro(w, http.StatusNotFound, errors.New("publicação não encontrada"))
+		return
+	}
+
+	bloqueado, erro := repositorios.NovoRepositorioDeUsuarios(db).ExisteBloqueio(usuarioID, publicacao.AutorID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if bloqueado {
+		respostas.Erro(w, http.StatusNotFound, errors.New("publicação não encontrada"))
+		return
+	}
+
+	publica, erro := repositorioPublicacoes.PodeVer(publicacaoId, 0)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if !publica {
+		respostas.Erro(w, http.StatusForbidden, errors.New("apenas publicações públicas podem ser repostadas"))
+		return
+	}
+
+	repositorio := repositorios.NovoRepositorioDeRepostagens(db)
+	repostada, erro := repositorio.Repostar(publicacaoId, usuarioID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if !repostada {
+		respostas.JSON(w, http.StatusNoContent, nil)
+		return
+	}
+
+	if erro = divulgacao.Notificar(db, publicacao.AutorID, usuarioID, modelos.NotificacaoRepostagem, publicacaoId); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	usuario, erro := repositorios.NovoRepositorioDeUsuarios(db).BuscarUsuarioPorID(usuarioID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	audiencia, erro := repositorio.BuscarAudiencia(usuarioID, publicacao.AutorID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	publicacao.Repostagens++
+	publicacao.Repostagem = &modelos.Repostagem{UsuarioID: usuarioID, UsuarioNick: usuario.Nick, CriadaEm: time.Now()}
+	eventos.Publicar(audiencia, eventos.EventoPublicacao, publicacao)
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+// DesfazerRepostagem remove a repostagem de uma publicação feita pelo usuário logado
+func DesfazerRepostagem(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	publicacaoId, erro := strconv.ParseUint(parametros["publicacaoId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	repositorio := repositorios.NovoRepositorioDeRepostagens(db)
+	if erro = repositorio.DesfazerRepostagem(publicacaoId, usuarioID); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"api/src/autenticacao"
+	"api/src/banco"
+	"api/src/config"
+	"api/src/modelos"
+	"api/src/repositorios"
+	"api/src/respostas"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// limiteSugestoesCalculadas é quantas sugestões são calculadas e guardadas para cada usuário
+const limiteSugestoesCalculadas = 50
+
+type sugestoesCalculadas struct {
+	sugestoes    []modelos.Sugestao
+	calculadasEm time.Time
+}
+
+// cacheSugestoes guarda as sugestões de cada usuário por config.ValidadeSugestoes, já que
+// calculá-las percorre todo o grafo de seguidores
+var cacheSugestoes = struct {
+	sync.Mutex
+	usuarios map[uint64]sugestoesCalculadas
+}{usuarios: make(map[uint64]sugestoesCalculadas)}
+
+// BuscarSugestoes retorna usuários que o usuário logado pode querer seguir, com o motivo de cada sugestão
+func BuscarSugestoes(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	limite, deslocamento := extrairPaginacao(r)
+
+	sugestoes, encontradas := sugestoesGuardadas(usuarioID)
+	if !encontradas {
+		db, erro := banco.Conectar()
+		if erro != nil {
+			respostas.Erro(w, http.StatusInternalServerError, erro)
+			return
+		}
+
+		defer db.Close()
+
+		repositorio := repositorios.NovoRepositorioDeUsuarios(db)
+		sugestoes, erro = repositorio.BuscarSugestoes(usuarioID, limiteSugestoesCalculadas)
+		if erro != nil {
+			respostas.Erro(w, http.StatusInternalServerError, erro)
+			return
+		}
+
+		guardarSugestoes(usuarioID, sugestoes)
+	}
+
+	if deslocamento >= uint64(len(sugestoes)) {
+		respostas.JSON(w, http.StatusOK, []modelos.Sugestao{})
+		return
+	}
+
+	sugestoes = sugestoes[deslocamento:]
+	if uint64(len(sugestoes)) > limite {
+		sugestoes = sugestoes[:limite]
+	}
+
+	respostas.JSON(w, http.StatusOK, sugestoes)
+}
+
+func sugestoesGuardadas(usuarioID uint64) ([]modelos.Sugestao, bool) {
+	cacheSugestoes.Lock()
+	defer cacheSugestoes.Unlock()
+
+	calculadas, encontradas := cacheSugestoes.usuarios[usuarioID]
+	if !encontradas || time.Since(calculadas.calculadasEm) > config.ValidadeSugestoes {
+		return nil, false
+	}
+
+	return calculadas.sugestoes, true
+}
+
+// guardarSugestoes salva as sugestões calculadas, aproveitando para descartar as que já expiraram
+func guardarSugestoes(usuarioID uint64, sugestoes []modelos.Sugestao) {
+	cacheSugestoes.Lock()
+	defer cacheSugestoes.Unlock()
+
+	for id, calculadas := range cacheSugestoes.usuarios {
+		if time.Since(calculadas.calculadasEm) > config.ValidadeSugestoes {
+			delete(cacheSugestoes.usuarios, id)
+		}
+	}
+
+	cacheSugestoes.usuarios[usuarioID] = sugestoesCalculadas{sugestoes, time.Now()}
+}
+
+// invalidarSugestoes descarta as sugestões guardadas dos usuários, que serão recalculadas na próxima busca
+func invalidarSugestoes(usuariosIDs ...uint64) {
+	cacheSugestoes.Lock()
+	defer cacheSugestoes.Unlock()
+
+	for _, usuarioID := range usuariosIDs {
+		delete(cacheSugestoes.usuarios, usuarioID)
+	}
+}
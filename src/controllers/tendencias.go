@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"api/src/autenticacao"
+	"api/src/banco"
+	"api/src/modelos"
+	"api/src/repositorios"
+	"api/src/respostas"
+	"errors"
+	"net/http"
+)
+
+// Tipos de tendência que podem ser pedidos em /tendencias
+const (
+	tipoTendenciaPublicacoes = "publicacoes"
+	tipoTendenciaTags        = "tags"
+)
+
+// BuscarTendencias retorna as publicações e tags em alta na janela informada (1h, 24h ou 7d).
+// O parâmetro tipo restringe a resposta a publicacoes ou tags e tag filtra as publicações
+func BuscarTendencias(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := r.URL.Query()
+
+	janela := parametros.Get("janela")
+	if janela == "" {
+		janela = modelos.JanelaPadrao
+	}
+
+	if _, existe := modelos.Janelas[janela]; !existe {
+		respostas.Erro(w, http.StatusBadRequest, errors.New("janela inválida, use 1h, 24h ou 7d"))
+		return
+	}
+
+	tipo := parametros.Get("tipo")
+	if tipo != "" && tipo != tipoTendenciaPublicacoes && tipo != tipoTendenciaTags {
+		respostas.Erro(w, http.StatusBadRequest, errors.New("tipo inválido, use publicacoes ou tags"))
+		return
+	}
+
+	var tag string
+	if valor := parametros.Get("tag"); valor != "" {
+		if tag = modelos.NormalizarTag(valor); tag == "" {
+			respostas.Erro(w, http.StatusBadRequest, errors.New("tag inválida"))
+			return
+		}
+	}
+
+	limite, _ := extrairPaginacao(r)
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	repositorio := repositorios.NovoRepositorioDeTendencias(db)
+	tendencias := modelos.Tendencias{Janela: janela}
+
+	if tipo != tipoTendenciaTags {
+		if tendencias.Publicacoes, erro = repositorio.BuscarPublicacoes(janela, tag, usuarioID, limite); erro != nil {
+			respostas.Erro(w, http.StatusInternalServerError, erro)
+			return
+		}
+	}
+
+	if tipo != tipoTendenciaPublicacoes {
+		if tendencias.Tags, erro = repositorio.BuscarTags(janela, limite); erro != nil {
+			respostas.Erro(w, http.StatusInternalServerError, erro)
+			return
+		}
+	}
+
+	respostas.JSON(w, http.StatusOK, tendencias)
+}
@@ -3,12 +3,16 @@ package controllers
 import (
 	"api/src/autenticacao"
 	"api/src/banco"
+	"api/src/config"
+	"api/src/divulgacao"
 	"api/src/modelos"
 	"api/src/repositorios"
 	"api/src/respostas"
 	"api/src/seguranca"
+	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"strconv"
@@ -54,9 +58,25 @@ func CriarUsuario(w http.ResponseWriter, r *http.Request) {
 	respostas.JSON(w, http.StatusCreated, usuario)
 }
 
+// limiteSugestaoUsuarios é quantos usuários o autocompletar retorna
+const limiteSugestaoUsuarios = 8
+
 // BuscarUsuario busca usuarios no banco de dados
 func BuscarUsuarios(w http.ResponseWriter, r *http.Request) {
-	nomeOuNick := strings.ToLower(r.URL.Query().Get("usuario"))
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	nomeOuNick := strings.TrimSpace(r.URL.Query().Get("usuario"))
+	if !buscaDeUsuarioValida(nomeOuNick) {
+		respostas.Erro(w, http.StatusBadRequest,
+			fmt.Errorf("a busca deve ter ao menos %d caracteres", modelos.TamanhoMinimoBuscaUsuarios))
+		return
+	}
+
+	limite, deslocamento := extrairPaginacao(r)
 
 	db, erro := banco.Conectar()
 	if erro != nil {
@@ -67,7 +87,7 @@ func BuscarUsuarios(w http.ResponseWriter, r *http.Request) {
 	defer db.Close()
 
 	repositorio := repositorios.NovoRepositorioDeUsuarios(db)
-	usuarios, erro := repositorio.BuscarUsuarios(nomeOuNick)
+	usuarios, erro := repositorio.BuscarUsuarios(nomeOuNick, usuarioID, limite, deslocamento)
 	if erro != nil {
 		respostas.Erro(w, http.StatusInternalServerError, erro)
 		return
@@ -76,6 +96,43 @@ func BuscarUsuarios(w http.ResponseWriter, r *http.Request) {
 	respostas.JSON(w, http.StatusOK, usuarios)
 }
 
+// SugerirUsuarios completa o nome ou nick que está sendo digitado com poucos usuários.
+// Buscas curtas demais retornam uma lista vazia
+func SugerirUsuarios(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	nomeOuNick := strings.TrimSpace(r.URL.Query().Get("q"))
+	if !buscaDeUsuarioValida(nomeOuNick) {
+		respostas.JSON(w, http.StatusOK, []modelos.UsuarioResumido{})
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	repositorio := repositorios.NovoRepositorioDeUsuarios(db)
+	usuarios, erro := repositorio.SugerirUsuarios(nomeOuNick, usuarioID, limiteSugestaoUsuarios)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusOK, usuarios)
+}
+
+func buscaDeUsuarioValida(nomeOuNick string) bool {
+	return len([]rune(strings.TrimPrefix(nomeOuNick, "@"))) >= modelos.TamanhoMinimoBuscaUsuarios
+}
+
 // BuscarUsuario busca um usuario no banco de dados
 func BuscarUsuario(w http.ResponseWriter, r *http.Request) {
 	parametros := mux.Vars(r)
@@ -198,6 +255,54 @@ func DeletarUsuario(w http.ResponseWriter, r *http.Request) {
 
 }
 
+// RestaurarUsuario desfaz a exclusão de uma conta dentro do prazo configurado, a partir do e-mail e senha
+func RestaurarUsuario(w http.ResponseWriter, r *http.Request) {
+	corpoRequest, erro := ioutil.ReadAll(r.Body)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnprocessableEntity, erro)
+		return
+	}
+
+	var usuario modelos.Usuario
+	if erro = json.Unmarshal(corpoRequest, &usuario); erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	repositorio := repositorios.NovoRepositorioDeUsuarios(db)
+	usuarioSalvoNoBanco, erro := repositorio.BuscarExcluidoPorEmail(usuario.Email)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if erro = seguranca.VerificaSenha(usuarioSalvoNoBanco.Senha, usuario.Senha); erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	restaurado, erro := repositorio.Restaurar(usuarioSalvoNoBanco.ID, config.PrazoRestauracaoConta)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if !restaurado {
+		respostas.Erro(w, http.StatusGone, errors.New("o prazo para restaurar esta conta terminou"))
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
 func SeguirUsuario(w http.ResponseWriter, r *http.Request) {
 	seguidorID, erro := autenticacao.ExtrairUsuarioID(r)
 	if erro != nil {
@@ -226,14 +331,71 @@ func SeguirUsuario(w http.ResponseWriter, r *http.Request) {
 	defer db.Close()
 
 	repositorio := repositorios.NovoRepositorioDeUsuarios(db)
+	bloqueado, erro := repositorio.ExisteBloqueio(usuarioId, seguidorID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if bloqueado {
+		respostas.Erro(w, http.StatusForbidden, errors.New("Não é possivel seguir este usuário"))
+		return
+	}
+
+	pendente, erro := solicitarSeguirSeNecessario(db, usuarioId, seguidorID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if pendente {
+		respostas.JSON(w, http.StatusAccepted, nil)
+		return
+	}
+
 	if erro = repositorio.Seguir(usuarioId, seguidorID); erro != nil {
 		respostas.Erro(w, http.StatusInternalServerError, erro)
 		return
 	}
 
+	if erro = divulgacao.Notificar(db, usuarioId, seguidorID, modelos.NotificacaoSeguidor, 0); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	invalidarSugestoes(seguidorID)
+
 	respostas.JSON(w, http.StatusNoContent, nil)
 }
 
+// solicitarSeguirSeNecessario registra um pedido para seguir quando a conta é privada e o seguidor
+// ainda não a segue, avisando o dono da conta. Retorna se o seguimento ficou pendente
+func solicitarSeguirSeNecessario(db *sql.DB, usuarioID, seguidorID uint64) (bool, error) {
+	repositorio := repositorios.NovoRepositorioDeUsuarios(db)
+	privada, erro := repositorio.ContaPrivada(usuarioID)
+	if erro != nil || !privada {
+		return false, erro
+	}
+
+	segue, erro := repositorio.Segue(usuarioID, seguidorID)
+	if erro != nil || segue {
+		return false, erro
+	}
+
+	nova, erro := repositorio.SolicitarSeguir(usuarioID, seguidorID)
+	if erro != nil {
+		return false, erro
+	}
+
+	if nova {
+		if erro = divulgacao.Notificar(db, usuarioID, seguidorID, modelos.NotificacaoSolicitacao, 0); erro != nil {
+			return false, erro
+		}
+	}
+
+	return true, nil
+}
+
 func PararDeSeguirUsuario(w http.ResponseWriter, r *http.Request) {
 	seguidorID, erro := autenticacao.ExtrairUsuarioID(r)
 	if erro != nil {
@@ -267,6 +429,8 @@ func PararDeSeguirUsuario(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	invalidarSugestoes(seguidorID)
+
 	respostas.JSON(w, http.StatusNoContent, nil)
 }
 
@@ -322,6 +486,85 @@ func BuscarSeguindo(w http.ResponseWriter, r *http.Request) {
 	respostas.JSON(w, http.StatusOK, seguidores)
 }
 
+// BuscarRelacao retorna como o usuário logado se relaciona com outro usuário
+func BuscarRelacao(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	usuarioId, erro := strconv.ParseUint(parametros["usuarioId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	repositorio := repositorios.NovoRepositorioDeUsuarios(db)
+	usuario, erro := repositorio.BuscarUsuarioPorID(usuarioId)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if usuario.ID == 0 {
+		respostas.Erro(w, http.StatusNotFound, errors.New("usuário não encontrado"))
+		return
+	}
+
+	relacao, erro := repositorio.BuscarRelacao(usuarioID, usuarioId)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusOK, relacao)
+}
+
+// BuscarSeguidoresEmComum retorna os seguidores de um usuário que o usuário logado também segue
+func BuscarSeguidoresEmComum(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	usuarioId, erro := strconv.ParseUint(parametros["usuarioId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	limite, deslocamento := extrairPaginacao(r)
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	repositorio := repositorios.NovoRepositorioDeUsuarios(db)
+	usuarios, erro := repositorio.BuscarSeguidoresEmComum(usuarioID, usuarioId, limite, deslocamento)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusOK, usuarios)
+}
+
 func TrocarSenha(w http.ResponseWriter, r *http.Request) {
 	parametros := mux.Vars(r)
 	usuarioId, erro := strconv.ParseUint(parametros["usuarioId"], 10, 64)
@@ -381,3 +624,266 @@ func TrocarSenha(w http.ResponseWriter, r *http.Request) {
 
 	respostas.JSON(w, http.StatusNoContent, nil)
 }
+
+func BloquearUsuario(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	bloqueadoId, erro := strconv.ParseUint(parametros["usuarioId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	if usuarioID == bloqueadoId {
+		respostas.Erro(w, http.StatusBadRequest, errors.New("Não é possivel bloquear você mesmo"))
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	repositorio := repositorios.NovoRepositorioDeUsuarios(db)
+	if erro = repositorio.Bloquear(usuarioID, bloqueadoId); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	invalidarSugestoes(usuarioID, bloqueadoId)
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+func DesbloquearUsuario(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	bloqueadoId, erro := strconv.ParseUint(parametros["usuarioId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	repositorio := repositorios.NovoRepositorioDeUsuarios(db)
+	if erro = repositorio.Desbloquear(usuarioID, bloqueadoId); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	invalidarSugestoes(usuarioID, bloqueadoId)
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+// SilenciarUsuario faz com que o usuário logado deixe de ver as publicações e repostagens de outro
+// usuário nos feeds e de receber notificações dele, sem que ele fique sabendo
+func SilenciarUsuario(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	silenciadoId, erro := strconv.ParseUint(parametros["usuarioId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	if usuarioID == silenciadoId {
+		respostas.Erro(w, http.StatusBadRequest, errors.New("Não é possivel silenciar você mesmo"))
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	repositorio := repositorios.NovoRepositorioDeUsuarios(db)
+	usuario, erro := repositorio.BuscarUsuarioPorID(silenciadoId)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if usuario.ID == 0 {
+		respostas.Erro(w, http.StatusNotFound, errors.New("usuário não encontrado"))
+		return
+	}
+
+	if erro = repositorio.Silenciar(usuarioID, silenciadoId); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+// DeixarDeSilenciarUsuario desfaz o silenciamento de um usuário
+func DeixarDeSilenciarUsuario(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	silenciadoId, erro := strconv.ParseUint(parametros["usuarioId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	repositorio := repositorios.NovoRepositorioDeUsuarios(db)
+	if erro = repositorio.DeixarDeSilenciar(usuarioID, silenciadoId); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+// BuscarSolicitacoes retorna os pedidos para seguir o usuário logado que aguardam aprovação
+func BuscarSolicitacoes(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	limite, deslocamento := extrairPaginacao(r)
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	repositorio := repositorios.NovoRepositorioDeUsuarios(db)
+	usuarios, erro := repositorio.BuscarSolicitacoes(usuarioID, limite, deslocamento)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusOK, usuarios)
+}
+
+// AceitarSolicitacao aceita o pedido de um usuário para seguir o usuário logado
+func AceitarSolicitacao(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	seguidorId, erro := strconv.ParseUint(parametros["usuarioId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	repositorio := repositorios.NovoRepositorioDeUsuarios(db)
+	aceita, erro := repositorio.AceitarSolicitacao(usuarioID, seguidorId)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if !aceita {
+		respostas.Erro(w, http.StatusNotFound, errors.New("pedido para seguir não encontrado"))
+		return
+	}
+
+	if erro = divulgacao.Notificar(db, seguidorId, usuarioID, modelos.NotificacaoSolicitacaoAceita, 0); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	invalidarSugestoes(seguidorId)
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+// RecusarSolicitacao descarta o pedido de um usuário para seguir o usuário logado
+func RecusarSolicitacao(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	seguidorId, erro := strconv.ParseUint(parametros["usuarioId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	defer db.Close()
+
+	repositorio := repositorios.NovoRepositorioDeUsuarios(db)
+	recusada, erro := repositorio.RecusarSolicitacao(usuarioID, seguidorId)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if !recusada {
+		respostas.Erro(w, http.StatusNotFound, errors.New("pedido para seguir não encontrado"))
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
@@ -0,0 +1,85 @@
+// Package divulgacao reúne o envio de notificações e eventos que acompanha as ações sobre
+// publicações e usuários. Fica fora dos controllers para ser usado também pelas tarefas
+package divulgacao
+
+import (
+	"api/src/eventos"
+	"api/src/modelos"
+	"api/src/repositorios"
+	"database/sql"
+	"time"
+)
+
+// Publicacao notifica os usuários mencionados que podem ver a publicação e o autor da
+// publicação citada, e envia a publicação para o feed da audiência.
+// Deve ser chamada uma única vez, quando a publicação passa a estar publicada
+func Publicacao(db *sql.DB, publicacao modelos.Publicacao) error {
+	repositorio := repositorios.NovoRepositorioDePublicacoes(db)
+
+	if erro := NotificarMencoes(db, publicacao, publicacao.Mencoes); erro != nil {
+		return erro
+	}
+
+	if publicacao.CitacaoID != 0 {
+		citada, erro := repositorio.BuscarPublicacao(publicacao.CitacaoID)
+		if erro != nil {
+			return erro
+		}
+
+		if citada.ID != 0 {
+			if erro = Notificar(db, citada.AutorID, publicacao.AutorID, modelos.NotificacaoCitacao, citada.ID); erro != nil {
+				return erro
+			}
+		}
+	}
+
+	audiencia, erro := repositorio.BuscarAudiencia(publicacao)
+	if erro != nil {
+		return erro
+	}
+
+	eventos.Publicar(audiencia, eventos.EventoPublicacao, publicacao)
+
+	return nil
+}
+
+// NotificarMencoes avisa os usuários mencionados que podem ver a publicação
+func NotificarMencoes(db *sql.DB, publicacao modelos.Publicacao, mencoes []modelos.Mencao) error {
+	repositorio := repositorios.NovoRepositorioDePublicacoes(db)
+
+	for _, mencao := range mencoes {
+		podeVer, erro := repositorio.PodeVer(publicacao.ID, mencao.UsuarioID)
+		if erro != nil {
+			return erro
+		}
+
+		if !podeVer {
+			continue
+		}
+
+		if erro = Notificar(db, mencao.UsuarioID, publicacao.AutorID, modelos.NotificacaoMencao, publicacao.ID); erro != nil {
+			return erro
+		}
+	}
+
+	return nil
+}
+
+// Notificar registra uma notificação e a envia em tempo real ao destinatário
+func Notificar(db *sql.DB, usuarioID, atorID uint64, tipo string, publicacaoID uint64) error {
+	repositorio := repositorios.NovoRepositorioDeNotificacoes(db)
+	criada, erro := repositorio.Criar(usuarioID, atorID, tipo, publicacaoID)
+	if erro != nil || !criada {
+		return erro
+	}
+
+	eventos.Publicar([]uint64{usuarioID}, eventos.EventoNotificacao, modelos.Notificacao{
+		Tipo:         tipo,
+		PublicacaoID: publicacaoID,
+		AtorID:       atorID,
+		Total:        1,
+		CriadaEm:     time.Now(),
+	})
+
+	return nil
+}
@@ -0,0 +1,130 @@
+package estatisticas
+
+import (
+	"api/src/config"
+	"api/src/modelos"
+	"sync"
+	"time"
+)
+
+// Contador acumula em memória as impressões e visualizações de publicações até que sejam
+// gravadas no banco. Cada leitor conta uma vez por publicação dentro de config.JanelaVisualizacoes
+// e o autor nunca conta para as próprias publicações
+type Contador struct {
+	mutex     sync.Mutex
+	vistas    map[vista]time.Time
+	pendentes map[publicacaoNoDia]*modelos.ContagemVisualizacoes
+}
+
+// vista identifica a impressão ou visualização de uma publicação por um leitor
+type vista struct {
+	publicacaoID uint64
+	leitorID     uint64
+	detalhe      bool
+}
+
+type publicacaoNoDia struct {
+	publicacaoID uint64
+	dia          string
+}
+
+// Padrao é o contador usado pela API
+var Padrao = NovoContador()
+
+// NovoContador cria um contador vazio
+func NovoContador() *Contador {
+	return &Contador{
+		vistas:    make(map[vista]time.Time),
+		pendentes: make(map[publicacaoNoDia]*modelos.ContagemVisualizacoes),
+	}
+}
+
+// RegistrarImpressoes conta que as publicações apareceram para o leitor em um feed
+func (contador *Contador) RegistrarImpressoes(leitorID uint64, publicacoes []modelos.Publicacao) {
+	contador.mutex.Lock()
+	defer contador.mutex.Unlock()
+
+	agora := time.Now()
+	for _, publicacao := range publicacoes {
+		if contador.novaVista(vista{publicacao.ID, leitorID, false}, publicacao.AutorID, agora) {
+			contador.contagem(publicacao.ID, agora).Impressoes++
+		}
+	}
+}
+
+// RegistrarVisualizacao conta que o leitor abriu a publicação
+func (contador *Contador) RegistrarVisualizacao(leitorID uint64, publicacao modelos.Publicacao) {
+	contador.mutex.Lock()
+	defer contador.mutex.Unlock()
+
+	agora := time.Now()
+	if contador.novaVista(vista{publicacao.ID, leitorID, true}, publicacao.AutorID, agora) {
+		contador.contagem(publicacao.ID, agora).Visualizacoes++
+	}
+}
+
+// Esvaziar retorna as contagens acumuladas desde a última chamada e esquece as vistas cuja
+// janela já passou
+func (contador *Contador) Esvaziar() []modelos.ContagemVisualizacoes {
+	contador.mutex.Lock()
+	defer contador.mutex.Unlock()
+
+	limite := time.Now().Add(-config.JanelaVisualizacoes)
+	for chave, vistaEm := range contador.vistas {
+		if vistaEm.Before(limite) {
+			delete(contador.vistas, chave)
+		}
+	}
+
+	contagens := make([]modelos.ContagemVisualizacoes, 0, len(contador.pendentes))
+	for _, contagem := range contador.pendentes {
+		contagens = append(contagens, *contagem)
+	}
+
+	contador.pendentes = make(map[publicacaoNoDia]*modelos.ContagemVisualizacoes)
+	return contagens
+}
+
+// Devolver recoloca contagens que não puderam ser gravadas, para que entrem na próxima gravação
+func (contador *Contador) Devolver(contagens []modelos.ContagemVisualizacoes) {
+	contador.mutex.Lock()
+	defer contador.mutex.Unlock()
+
+	for _, contagem := range contagens {
+		chave := publicacaoNoDia{contagem.PublicacaoID, contagem.Dia}
+		if pendente, existe := contador.pendentes[chave]; existe {
+			pendente.Impressoes += contagem.Impressoes
+			pendente.Visualizacoes += contagem.Visualizacoes
+			continue
+		}
+
+		devolvida := contagem
+		contador.pendentes[chave] = &devolvida
+	}
+}
+
+// novaVista indica se a vista deve ser contada e, nesse caso, guarda o momento dela
+func (contador *Contador) novaVista(chave vista, autorID uint64, agora time.Time) bool {
+	if chave.publicacaoID == 0 || chave.leitorID == autorID {
+		return false
+	}
+
+	if vistaEm, existe := contador.vistas[chave]; existe && agora.Sub(vistaEm) < config.JanelaVisualizacoes {
+		return false
+	}
+
+	contador.vistas[chave] = agora
+	return true
+}
+
+func (contador *Contador) contagem(publicacaoID uint64, agora time.Time) *modelos.ContagemVisualizacoes {
+	chave := publicacaoNoDia{publicacaoID, agora.Format(modelos.FormatoDia)}
+
+	contagem, existe := contador.pendentes[chave]
+	if !existe {
+		contagem = &modelos.ContagemVisualizacoes{PublicacaoID: publicacaoID, Dia: chave.dia}
+		contador.pendentes[chave] = contagem
+	}
+
+	return contagem
+}
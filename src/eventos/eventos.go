@@ -0,0 +1,170 @@
+package eventos
+
+import (
+	"sync"
+	"time"
+)
+
+// Tipos de evento enviados aos clientes conectados
+const (
+	EventoPublicacao  = "publicacao"
+	EventoNotificacao = "notificacao"
+	EventoCurtidas    = "curtidas"
+	EventoReacoes     = "reacoes"
+	EventoMensagem    = "mensagem"
+)
+
+const (
+	// tamanhoFila é quantos eventos podem aguardar envio em uma conexão antes dela ser descartada
+	tamanhoFila = 64
+	// tamanhoHistorico é quantos eventos recentes são guardados por usuário para retomada
+	tamanhoHistorico = 100
+	// validadeHistorico é por quanto tempo um evento fica guardado para retomada. Usuários sem
+	// eventos recentes saem do histórico, para que ele não cresça com todos que já receberam algo
+	validadeHistorico = 10 * time.Minute
+)
+
+// Evento representa uma mensagem enviada em tempo real a um usuário
+type Evento struct {
+	ID    uint64      `json:"id"`
+	Tipo  string      `json:"tipo"`
+	Dados interface{} `json:"dados"`
+
+	publicadoEm time.Time
+}
+
+// Assinatura representa uma conexão de um usuário recebendo eventos
+type Assinatura struct {
+	Eventos   chan Evento
+	usuarioID uint64
+}
+
+// Hub distribui os eventos publicados para as assinaturas de cada usuário
+type Hub struct {
+	mutex         sync.Mutex
+	ultimoID      uint64
+	assinaturas   map[uint64]map[*Assinatura]bool
+	historico     map[uint64][]Evento
+	ultimaLimpeza time.Time
+}
+
+// NovoHub cria um hub de eventos vazio
+func NovoHub() *Hub {
+	return &Hub{
+		assinaturas: make(map[uint64]map[*Assinatura]bool),
+		historico:   make(map[uint64][]Evento),
+	}
+}
+
+// Padrao é o hub usado pela API
+var Padrao = NovoHub()
+
+// Publicar envia um evento do hub padrão aos usuários informados
+func Publicar(usuariosIDs []uint64, tipo string, dados interface{}) {
+	Padrao.Publicar(usuariosIDs, tipo, dados)
+}
+
+// Publicar envia um evento aos usuários informados. Conexões que não consomem
+// os eventos a tempo são encerradas para não segurar as demais
+func (hub *Hub) Publicar(usuariosIDs []uint64, tipo string, dados interface{}) {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	agora := time.Now()
+	if agora.Sub(hub.ultimaLimpeza) >= validadeHistorico {
+		hub.limparHistorico(agora)
+	}
+
+	hub.ultimoID++
+	evento := Evento{ID: hub.ultimoID, Tipo: tipo, Dados: dados, publicadoEm: agora}
+
+	enviados := make(map[uint64]bool, len(usuariosIDs))
+	for _, usuarioID := range usuariosIDs {
+		if enviados[usuarioID] {
+			continue
+		}
+		enviados[usuarioID] = true
+
+		historico := append(hub.historico[usuarioID], evento)
+		if len(historico) > tamanhoHistorico {
+			historico = historico[len(historico)-tamanhoHistorico:]
+		}
+		hub.historico[usuarioID] = historico
+
+		for assinatura := range hub.assinaturas[usuarioID] {
+			select {
+			case assinatura.Eventos <- evento:
+			default:
+				hub.remover(assinatura)
+			}
+		}
+	}
+}
+
+// Assinar registra uma conexão do usuário e retorna os eventos guardados
+// posteriores a ultimoID, para retomada após uma reconexão
+func (hub *Hub) Assinar(usuarioID, ultimoID uint64) (*Assinatura, []Evento) {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	assinatura := &Assinatura{Eventos: make(chan Evento, tamanhoFila), usuarioID: usuarioID}
+	if hub.assinaturas[usuarioID] == nil {
+		hub.assinaturas[usuarioID] = make(map[*Assinatura]bool)
+	}
+	hub.assinaturas[usuarioID][assinatura] = true
+
+	var pendentes []Evento
+	if ultimoID > 0 {
+		limite := time.Now().Add(-validadeHistorico)
+		for _, evento := range hub.historico[usuarioID] {
+			if evento.ID > ultimoID && evento.publicadoEm.After(limite) {
+				pendentes = append(pendentes, evento)
+			}
+		}
+	}
+
+	return assinatura, pendentes
+}
+
+// Cancelar remove uma assinatura do hub
+func (hub *Hub) Cancelar(assinatura *Assinatura) {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	hub.remover(assinatura)
+}
+
+// limparHistorico descarta os eventos vencidos e os usuários que ficaram sem eventos guardados
+func (hub *Hub) limparHistorico(agora time.Time) {
+	limite := agora.Add(-validadeHistorico)
+
+	for usuarioID, historico := range hub.historico {
+		// Os eventos estão em ordem de publicação, então os vencidos ficam no início
+		vencidos := 0
+		for vencidos < len(historico) && !historico[vencidos].publicadoEm.After(limite) {
+			vencidos++
+		}
+
+		if vencidos == len(historico) {
+			delete(hub.historico, usuarioID)
+		} else if vencidos > 0 {
+			hub.historico[usuarioID] = append([]Evento(nil), historico[vencidos:]...)
+		}
+	}
+
+	hub.ultimaLimpeza = agora
+}
+
+func (hub *Hub) remover(assinatura *Assinatura) {
+	assinaturas := hub.assinaturas[assinatura.usuarioID]
+	if !assinaturas[assinatura] {
+		return
+	}
+
+	delete(assinaturas, assinatura)
+	if len(assinaturas) == 0 {
+		delete(hub.assinaturas, assinatura.usuarioID)
+	}
+
+	close(assinatura.Eventos)
+}
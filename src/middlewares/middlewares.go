@@ -2,7 +2,11 @@ package middlewares
 
 import (
 	"api/src/autenticacao"
+	"api/src/banco"
+	"api/src/repositorios"
 	"api/src/respostas"
+	"database/sql"
+	"errors"
 	"log"
 	"net/http"
 )
@@ -15,13 +19,53 @@ func Logger(next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
-// Autenticar verifica se o usuário esta autenticado
+// Autenticar verifica se o usuário esta autenticado e se a conta dele pode ser usada
 func Autenticar(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if erro := autenticacao.ValidarToken(r); erro != nil {
 			respostas.Erro(w, http.StatusUnauthorized, erro)
 			return
 		}
+
+		usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+		if erro != nil {
+			respostas.Erro(w, http.StatusUnauthorized, erro)
+			return
+		}
+
+		db, erro := banco.Conectar()
+		if erro != nil {
+			respostas.Erro(w, http.StatusInternalServerError, erro)
+			return
+		}
+
+		repositorio := repositorios.NovoRepositorioDeUsuarios(db)
+		estado, erro := repositorio.BuscarEstado(usuarioID)
+		db.Close()
+
+		if erro == sql.ErrNoRows {
+			respostas.Erro(w, http.StatusUnauthorized, errors.New("usuário do token não existe"))
+			return
+		}
+
+		if erro != nil {
+			respostas.Erro(w, http.StatusInternalServerError, erro)
+			return
+		}
+
+		if erro = estado.PermiteAcesso(); erro != nil {
+			respostas.Erro(w, http.StatusForbidden, erro)
+			return
+		}
+
 		next(w, r)
 	}
 }
+
+// PermitirEscopo faz a rota aceitar os tokens de curta duração do escopo informado, inclusive
+// no parâmetro token da URL. Deve envolver o Autenticar
+func PermitirEscopo(escopo string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		next(w, autenticacao.PermitirEscopo(r, escopo))
+	}
+}
@@ -0,0 +1,150 @@
+package modelos
+
+import (
+	"html"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// TamanhoDestaque é quantos caracteres do conteúdo aparecem no trecho destacado de um resultado
+const TamanhoDestaque = 160
+
+// TamanhoMinimoBuscaUsuarios é quantos caracteres a busca de usuários precisa ter, sem contar o @
+const TamanhoMinimoBuscaUsuarios = 2
+
+// FiltroBusca representa os critérios de uma busca de publicações
+type FiltroBusca struct {
+	Termo     string
+	AutorNick string
+	Tag       string
+	De        *time.Time
+	Ate       *time.Time
+}
+
+// ResultadoBusca representa uma publicação encontrada na busca, com a relevância calculada e os
+// trechos em que os termos aparecem marcados com <mark></mark> (o restante do texto é escapado)
+type ResultadoBusca struct {
+	Publicacao Publicacao `json:"publicacao"`
+	Relevancia float64    `json:"relevancia"`
+	Destaques  Destaques  `json:"destaques"`
+}
+
+// Destaques são os trechos do título e do conteúdo com os termos buscados marcados
+type Destaques struct {
+	Titulo   string `json:"titulo"`
+	Conteudo string `json:"conteudo"`
+}
+
+var acentos = map[rune]rune{
+	'á': 'a', 'à': 'a', 'â': 'a', 'ã': 'a', 'ä': 'a',
+	'é': 'e', 'è': 'e', 'ê': 'e', 'ë': 'e',
+	'í': 'i', 'ì': 'i', 'î': 'i', 'ï': 'i',
+	'ó': 'o', 'ò': 'o', 'ô': 'o', 'õ': 'o', 'ö': 'o',
+	'ú': 'u', 'ù': 'u', 'û': 'u', 'ü': 'u',
+	'ç': 'c', 'ñ': 'n',
+}
+
+// NormalizarRuna deixa a letra em minúsculo e sem acento, para comparações que ignoram os dois
+func NormalizarRuna(r rune) rune {
+	r = unicode.ToLower(r)
+	if semAcento, existe := acentos[r]; existe {
+		return semAcento
+	}
+
+	return r
+}
+
+// NormalizarTexto deixa o texto em minúsculo e sem acentos
+func NormalizarTexto(texto string) string {
+	return strings.Map(NormalizarRuna, texto)
+}
+
+// ExtrairTermos separa as palavras de uma busca, já normalizadas e sem repetições
+func ExtrairTermos(busca string) []string {
+	var termos []string
+	encontrados := map[string]bool{}
+
+	for _, termo := range strings.FieldsFunc(NormalizarTexto(busca), SeparaTermos) {
+		if !encontrados[termo] {
+			encontrados[termo] = true
+			termos = append(termos, termo)
+		}
+	}
+
+	return termos
+}
+
+// SeparaTermos indica se o caractere separa as palavras de um texto
+func SeparaTermos(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+}
+
+// Destacar retorna um trecho de até tamanho caracteres do texto (ou o texto todo, se tamanho for 0)
+// começando pouco antes da primeira ocorrência de um dos termos, com as palavras iguais a algum
+// termo marcadas, sem diferenciar maiúsculas nem acentos. O texto é escapado para poder ser exibido como HTML
+func Destacar(texto string, termos []string, tamanho int) string {
+	original := []rune(texto)
+	normalizado := []rune(NormalizarTexto(texto))
+
+	marcadas := make([]bool, len(original))
+	primeira := -1
+
+	for i := range normalizado {
+		if i > 0 && !SeparaTermos(normalizado[i-1]) {
+			continue
+		}
+
+		for _, termo := range termos {
+			fim := i + len([]rune(termo))
+			if fim == i || fim > len(normalizado) || string(normalizado[i:fim]) != termo ||
+				(fim < len(normalizado) && !SeparaTermos(normalizado[fim])) {
+				continue
+			}
+
+			if primeira == -1 {
+				primeira = i
+			}
+
+			for j := i; j < fim; j++ {
+				marcadas[j] = true
+			}
+		}
+	}
+
+	inicio, fim := 0, len(original)
+	if tamanho > 0 && fim > tamanho {
+		if primeira > tamanho/4 {
+			inicio = primeira - tamanho/4
+		}
+		if inicio+tamanho > fim {
+			inicio = fim - tamanho
+		}
+		fim = inicio + tamanho
+	}
+
+	var trecho strings.Builder
+	if inicio > 0 {
+		trecho.WriteString("…")
+	}
+
+	for i := inicio; i < fim; {
+		j := i
+		for j < fim && marcadas[j] == marcadas[i] {
+			j++
+		}
+
+		parte := html.EscapeString(string(original[i:j]))
+		if marcadas[i] {
+			parte = "<mark>" + parte + "</mark>"
+		}
+		trecho.WriteString(parte)
+		i = j
+	}
+
+	if fim < len(original) {
+		trecho.WriteString("…")
+	}
+
+	return trecho.String()
+}
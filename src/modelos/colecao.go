@@ -0,0 +1,26 @@
+package modelos
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Colecao representa um agrupamento nomeado de publicações salvas por um usuário
+type Colecao struct {
+	ID       uint64    `json:"id,omitempty"`
+	Nome     string    `json:"nome,omitempty"`
+	Total    uint64    `json:"total"`
+	CriadaEm time.Time `json:"criadaEm,omitempty"`
+}
+
+// NormalizarNomeColecao remove os espaços das pontas do nome e verifica seu tamanho
+func NormalizarNomeColecao(nome string) (string, error) {
+	nome = strings.TrimSpace(nome)
+
+	if len([]rune(nome)) > 50 {
+		return "", errors.New("o nome da coleção não pode ter mais de 50 caracteres")
+	}
+
+	return nome, nil
+}
@@ -0,0 +1,35 @@
+package modelos
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// TamanhoMaximoComentario é quantos caracteres um comentário pode ter
+const TamanhoMaximoComentario = 500
+
+// Comentario representa a resposta de um usuário a uma publicação
+type Comentario struct {
+	ID           uint64    `json:"id,omitempty"`
+	PublicacaoID uint64    `json:"publicacaoId,omitempty"`
+	AutorID      uint64    `json:"autorId,omitempty"`
+	AutorNick    string    `json:"autorNick,omitempty"`
+	Conteudo     string    `json:"conteudo,omitempty"`
+	CriadoEm     time.Time `json:"criadoEm,omitempty"`
+}
+
+// Preparar valida e formata o comentário recebido
+func (comentario *Comentario) Preparar() error {
+	comentario.Conteudo = strings.TrimSpace(comentario.Conteudo)
+
+	if comentario.Conteudo == "" {
+		return errors.New("conteudo é obrigatório e nao pode estar em branco")
+	}
+
+	if len([]rune(comentario.Conteudo)) > TamanhoMaximoComentario {
+		return errors.New("conteudo não pode ter mais de 500 caracteres")
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package modelos
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Papéis de um usuário em uma comunidade
+const (
+	PapelMembro    = "membro"
+	PapelModerador = "moderador"
+	PapelDono      = "dono"
+)
+
+// Comunidade representa um grupo de usuários com publicações próprias
+type Comunidade struct {
+	ID        uint64    `json:"id,omitempty"`
+	Nome      string    `json:"nome,omitempty"`
+	Descricao string    `json:"descricao,omitempty"`
+	Privada   bool      `json:"privada"`
+	DonoID    uint64    `json:"donoId,omitempty"`
+	Membros   uint64    `json:"membros"`
+	CriadaEm  time.Time `json:"criadaEm,omitempty"`
+}
+
+// MembroComunidade representa um usuário e seu papel em uma comunidade
+type MembroComunidade struct {
+	ID       uint64    `json:"id,omitempty"`
+	Nome     string    `json:"nome,omitempty"`
+	Nick     string    `json:"nick,omitempty"`
+	Papel    string    `json:"papel,omitempty"`
+	EntrouEm time.Time `json:"entrouEm,omitempty"`
+}
+
+// Preparar valida e formata uma comunidade
+func (comunidade *Comunidade) Preparar() error {
+	comunidade.Nome = strings.TrimSpace(comunidade.Nome)
+	comunidade.Descricao = strings.TrimSpace(comunidade.Descricao)
+
+	if comunidade.Nome == "" {
+		return errors.New("nome é obrigatório e nao pode estar em branco")
+	}
+
+	if len([]rune(comunidade.Nome)) > 50 {
+		return errors.New("nome não pode ter mais de 50 caracteres")
+	}
+
+	if len([]rune(comunidade.Descricao)) > 500 {
+		return errors.New("descricao não pode ter mais de 500 caracteres")
+	}
+
+	return nil
+}
+
+// PodeModerar indica se o papel permite moderar a comunidade
+func PodeModerar(papel string) bool {
+	return papel == PapelModerador || papel == PapelDono
+}
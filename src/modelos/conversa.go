@@ -0,0 +1,47 @@
+package modelos
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// MaximoParticipantesConversa limita o tamanho das conversas em grupo
+const MaximoParticipantesConversa = 10
+
+// Conversa representa uma conversa privada entre dois ou mais usuários. Grupo é definido na
+// criação e não muda com a saída de participantes
+type Conversa struct {
+	ID             uint64    `json:"id,omitempty"`
+	Grupo          bool      `json:"grupo"`
+	Participantes  []Usuario `json:"participantes,omitempty"`
+	UltimaMensagem *Mensagem `json:"ultimaMensagem,omitempty"`
+	NaoLidas       uint64    `json:"naoLidas"`
+	CriadaEm       time.Time `json:"criadaEm,omitempty"`
+}
+
+// Mensagem representa uma mensagem enviada em uma conversa
+type Mensagem struct {
+	ID         uint64    `json:"id,omitempty"`
+	ConversaID uint64    `json:"conversaId,omitempty"`
+	AutorID    uint64    `json:"autorId,omitempty"`
+	AutorNick  string    `json:"autorNick,omitempty"`
+	Conteudo   string    `json:"conteudo,omitempty"`
+	LidaPor    []uint64  `json:"lidaPor,omitempty"`
+	CriadaEm   time.Time `json:"criadaEm,omitempty"`
+}
+
+// Preparar valida e formata uma mensagem
+func (mensagem *Mensagem) Preparar() error {
+	mensagem.Conteudo = strings.TrimSpace(mensagem.Conteudo)
+
+	if mensagem.Conteudo == "" {
+		return errors.New("conteudo é obrigatório e nao pode estar em branco")
+	}
+
+	if len([]rune(mensagem.Conteudo)) > 1000 {
+		return errors.New("conteudo não pode ter mais de 1000 caracteres")
+	}
+
+	return nil
+}
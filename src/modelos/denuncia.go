@@ -0,0 +1,101 @@
+package modelos
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Situações de uma denúncia
+const (
+	DenunciaAberta    = "aberta"
+	DenunciaEmAnalise = "em_analise"
+	DenunciaResolvida = "resolvida"
+)
+
+// Ações que um moderador pode tomar ao resolver uma denúncia
+const (
+	AcaoOcultarPublicacao = "ocultar_publicacao"
+	AcaoSuspenderUsuario  = "suspender_usuario"
+	AcaoDescartar         = "descartar"
+)
+
+// MaximoDiasSuspensao limita a duração de uma suspensão aplicada pela moderação
+const MaximoDiasSuspensao = 3650
+
+var motivosDenuncia = []string{"spam", "assedio", "discurso_de_odio", "conteudo_improprio", "outro"}
+
+// Denuncia representa uma denúncia de publicação ou usuário feita para a moderação
+type Denuncia struct {
+	ID            uint64     `json:"id,omitempty"`
+	DenuncianteID uint64     `json:"denuncianteId,omitempty"`
+	UsuarioID     uint64     `json:"usuarioId,omitempty"`
+	PublicacaoID  uint64     `json:"publicacaoId,omitempty"`
+	Motivo        string     `json:"motivo,omitempty"`
+	Descricao     string     `json:"descricao,omitempty"`
+	Status        string     `json:"status,omitempty"`
+	ModeradorID   uint64     `json:"moderadorId,omitempty"`
+	CriadaEm      time.Time  `json:"criadaEm,omitempty"`
+	ResolvidaEm   *time.Time `json:"resolvidaEm,omitempty"`
+}
+
+// DecisaoModeracao registra, de forma permanente, a ação tomada sobre uma denúncia
+type DecisaoModeracao struct {
+	ID            uint64    `json:"id,omitempty"`
+	DenunciaID    uint64    `json:"denunciaId,omitempty"`
+	ModeradorID   uint64    `json:"moderadorId,omitempty"`
+	Acao          string    `json:"acao,omitempty"`
+	Observacao    string    `json:"observacao,omitempty"`
+	DiasSuspensao uint64    `json:"diasSuspensao,omitempty"`
+	CriadaEm      time.Time `json:"criadaEm,omitempty"`
+}
+
+// Preparar valida e formata uma denúncia
+func (denuncia *Denuncia) Preparar() error {
+	denuncia.Motivo = strings.TrimSpace(denuncia.Motivo)
+	denuncia.Descricao = strings.TrimSpace(denuncia.Descricao)
+
+	motivoValido := false
+	for _, motivo := range motivosDenuncia {
+		if motivo == denuncia.Motivo {
+			motivoValido = true
+		}
+	}
+
+	if !motivoValido {
+		return errors.New("motivo inválido, use: " + strings.Join(motivosDenuncia, ", "))
+	}
+
+	if len([]rune(denuncia.Descricao)) > 500 {
+		return errors.New("descricao não pode ter mais de 500 caracteres")
+	}
+
+	return nil
+}
+
+// Preparar valida e formata uma decisão de moderação
+func (decisao *DecisaoModeracao) Preparar() error {
+	decisao.Observacao = strings.TrimSpace(decisao.Observacao)
+
+	switch decisao.Acao {
+	case AcaoOcultarPublicacao, AcaoDescartar:
+		decisao.DiasSuspensao = 0
+	case AcaoSuspenderUsuario:
+		if decisao.DiasSuspensao == 0 {
+			return errors.New("diasSuspensao é obrigatório para suspender um usuário")
+		}
+
+		if decisao.DiasSuspensao > MaximoDiasSuspensao {
+			return fmt.Errorf("diasSuspensao não pode passar de %d", MaximoDiasSuspensao)
+		}
+	default:
+		return errors.New("acao inválida")
+	}
+
+	if len([]rune(decisao.Observacao)) > 500 {
+		return errors.New("observacao não pode ter mais de 500 caracteres")
+	}
+
+	return nil
+}
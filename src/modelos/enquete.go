@@ -0,0 +1,75 @@
+package modelos
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Limites de opções de uma enquete
+const (
+	MinimoOpcoesEnquete = 2
+	MaximoOpcoesEnquete = 10
+)
+
+// Enquete representa uma votação anexada a uma publicação
+type Enquete struct {
+	Multipla          bool           `json:"multipla"`
+	EncerraEm         *time.Time     `json:"encerraEm,omitempty"`
+	OcultarResultados bool           `json:"ocultarResultados"`
+	Opcoes            []OpcaoEnquete `json:"opcoes"`
+
+	// Campos calculados para quem está lendo
+	Encerrada          bool     `json:"encerrada"`
+	ResultadosVisiveis bool     `json:"resultadosVisiveis"`
+	TotalVotantes      *uint64  `json:"totalVotantes,omitempty"`
+	MeusVotos          []uint64 `json:"meusVotos,omitempty"`
+}
+
+// OpcaoEnquete representa uma das opções de uma enquete. Votos só é preenchido se os
+// resultados estiverem visíveis para quem está lendo
+type OpcaoEnquete struct {
+	ID    uint64  `json:"id,omitempty"`
+	Texto string  `json:"texto"`
+	Votos *uint64 `json:"votos,omitempty"`
+}
+
+// Preparar valida e formata uma enquete antes de ser criada
+func (enquete *Enquete) Preparar() error {
+	if len(enquete.Opcoes) < MinimoOpcoesEnquete || len(enquete.Opcoes) > MaximoOpcoesEnquete {
+		return errors.New("a enquete deve ter de 2 a 10 opções")
+	}
+
+	textos := make(map[string]bool, len(enquete.Opcoes))
+	for i := range enquete.Opcoes {
+		opcao := &enquete.Opcoes[i]
+		opcao.ID = 0
+		opcao.Votos = nil
+		opcao.Texto = strings.TrimSpace(opcao.Texto)
+
+		if opcao.Texto == "" {
+			return errors.New("as opções da enquete não podem estar em branco")
+		}
+
+		if len([]rune(opcao.Texto)) > 100 {
+			return errors.New("as opções da enquete não podem ter mais de 100 caracteres")
+		}
+
+		chave := strings.ToLower(opcao.Texto)
+		if textos[chave] {
+			return errors.New("as opções da enquete não podem se repetir")
+		}
+		textos[chave] = true
+	}
+
+	if enquete.EncerraEm != nil && !enquete.EncerraEm.After(time.Now()) {
+		return errors.New("encerraEm deve ser uma data futura")
+	}
+
+	return nil
+}
+
+// EstaEncerrada indica se o prazo de votação da enquete já terminou
+func (enquete Enquete) EstaEncerrada() bool {
+	return enquete.EncerraEm != nil && !enquete.EncerraEm.After(time.Now())
+}
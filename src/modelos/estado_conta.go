@@ -0,0 +1,66 @@
+package modelos
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Estados possíveis de uma conta
+const (
+	EstadoAtivo      = "ativo"
+	EstadoSuspenso   = "suspenso"
+	EstadoBanido     = "banido"
+	EstadoDesativado = "desativado"
+)
+
+// EstadoConta representa a situação de uma conta e o motivo da última alteração
+type EstadoConta struct {
+	Estado      string     `json:"estado,omitempty"`
+	SuspensoAte *time.Time `json:"suspensoAte,omitempty"`
+	Motivo      string     `json:"motivo,omitempty"`
+	AlteradoPor uint64     `json:"alteradoPor,omitempty"`
+	CriadoEm    time.Time  `json:"criadoEm,omitempty"`
+}
+
+// Preparar valida e formata uma alteração de estado
+func (estado *EstadoConta) Preparar() error {
+	estado.Motivo = strings.TrimSpace(estado.Motivo)
+
+	switch estado.Estado {
+	case EstadoSuspenso:
+		if estado.SuspensoAte == nil || !estado.SuspensoAte.After(time.Now()) {
+			return errors.New("suspensoAte é obrigatório e deve ser uma data futura")
+		}
+	case EstadoAtivo, EstadoBanido, EstadoDesativado:
+		estado.SuspensoAte = nil
+	default:
+		return errors.New("estado inválido")
+	}
+
+	if estado.Motivo == "" {
+		return errors.New("motivo é obrigatório e nao pode estar em branco")
+	}
+
+	if len([]rune(estado.Motivo)) > 500 {
+		return errors.New("motivo não pode ter mais de 500 caracteres")
+	}
+
+	return nil
+}
+
+// PermiteAcesso retorna um erro explicando por que a conta não pode ser usada, ou nil
+func (estado EstadoConta) PermiteAcesso() error {
+	switch estado.Estado {
+	case EstadoSuspenso:
+		if estado.SuspensoAte != nil && estado.SuspensoAte.After(time.Now()) {
+			return errors.New("conta suspensa até " + estado.SuspensoAte.Format("02/01/2006 15:04"))
+		}
+	case EstadoBanido:
+		return errors.New("conta banida")
+	case EstadoDesativado:
+		return errors.New("conta desativada")
+	}
+
+	return nil
+}
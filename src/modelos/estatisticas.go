@@ -0,0 +1,51 @@
+package modelos
+
+import "time"
+
+// FormatoDia é o formato das datas usadas nas estatísticas
+const FormatoDia = "2006-01-02"
+
+// ContagemVisualizacoes acumula as impressões e visualizações de uma publicação em um dia
+type ContagemVisualizacoes struct {
+	PublicacaoID  uint64
+	Dia           string
+	Impressoes    uint64
+	Visualizacoes uint64
+}
+
+// Estatisticas resume o alcance das publicações de um usuário em um período. Impressões são as
+// vezes em que as publicações apareceram em um feed e visualizações as vezes em que foram abertas
+type Estatisticas struct {
+	De              string                  `json:"de"`
+	Ate             string                  `json:"ate"`
+	Impressoes      uint64                  `json:"impressoes"`
+	Visualizacoes   uint64                  `json:"visualizacoes"`
+	Curtidas        int64                   `json:"curtidas"`
+	Reacoes         uint64                  `json:"reacoes"`
+	NovosSeguidores uint64                  `json:"novosSeguidores"`
+	Seguidores      uint64                  `json:"seguidores"`
+	Dias            []EstatisticaDiaria     `json:"dias"`
+	TopPublicacoes  []EstatisticaPublicacao `json:"topPublicacoes"`
+}
+
+// EstatisticaDiaria são os números de um dia do período. Curtidas é o saldo do dia, já
+// descontadas as curtidas desfeitas
+type EstatisticaDiaria struct {
+	Dia             string `json:"dia"`
+	Impressoes      uint64 `json:"impressoes"`
+	Visualizacoes   uint64 `json:"visualizacoes"`
+	Curtidas        int64  `json:"curtidas"`
+	Reacoes         uint64 `json:"reacoes"`
+	NovosSeguidores uint64 `json:"novosSeguidores"`
+}
+
+// EstatisticaPublicacao são os números de uma publicação no período
+type EstatisticaPublicacao struct {
+	PublicacaoID  uint64    `json:"publicacaoId"`
+	Titulo        string    `json:"titulo"`
+	CriadaEm      time.Time `json:"criadaEm"`
+	Impressoes    uint64    `json:"impressoes"`
+	Visualizacoes uint64    `json:"visualizacoes"`
+	Curtidas      int64     `json:"curtidas"`
+	Reacoes       uint64    `json:"reacoes"`
+}
@@ -0,0 +1,41 @@
+package modelos
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Lista representa um grupo de usuários montado por alguém para acompanhar suas publicações
+type Lista struct {
+	ID            uint64    `json:"id,omitempty"`
+	Nome          string    `json:"nome,omitempty"`
+	Descricao     string    `json:"descricao,omitempty"`
+	Privada       bool      `json:"privada"`
+	DonoID        uint64    `json:"donoId,omitempty"`
+	DonoNick      string    `json:"donoNick,omitempty"`
+	Membros       uint64    `json:"membros"`
+	Seguidores    uint64    `json:"seguidores"`
+	SeguidaPorMim bool      `json:"seguidaPorMim"`
+	CriadaEm      time.Time `json:"criadaEm,omitempty"`
+}
+
+// Preparar valida e formata uma lista
+func (lista *Lista) Preparar() error {
+	lista.Nome = strings.TrimSpace(lista.Nome)
+	lista.Descricao = strings.TrimSpace(lista.Descricao)
+
+	if lista.Nome == "" {
+		return errors.New("nome é obrigatório e nao pode estar em branco")
+	}
+
+	if len([]rune(lista.Nome)) > 50 {
+		return errors.New("nome não pode ter mais de 50 caracteres")
+	}
+
+	if len([]rune(lista.Descricao)) > 200 {
+		return errors.New("descricao não pode ter mais de 200 caracteres")
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package modelos
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// regexMencao só aceita o @ no início do texto ou depois de um caractere que não faz parte de
+// nicks, para que e-mails como a@b.com não virem menções
+var regexMencao = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_.@])@([\p{L}\p{N}_.]+)`)
+
+// Mencao representa a citação de um usuário (@nick) em uma publicação
+type Mencao struct {
+	UsuarioID uint64 `json:"usuarioId"`
+	Nick      string `json:"nick"`
+	Campo     string `json:"campo"`
+	Inicio    int    `json:"inicio"`
+	Tamanho   int    `json:"tamanho"`
+}
+
+// extrairMencoes encontra as menções de um texto, com inicio e tamanho contados em caracteres
+func extrairMencoes(campo, texto string) []Mencao {
+	var mencoes []Mencao
+
+	for _, indices := range regexMencao.FindAllStringSubmatchIndex(texto, -1) {
+		// O ponto final de uma frase ("fala, @joao.") não faz parte do nick
+		nick := strings.TrimRight(texto[indices[2]:indices[3]], ".")
+		if nick == "" {
+			continue
+		}
+
+		// O @ fica logo antes do nick, já que o caractere que o antecede não entra na menção
+		inicio := indices[2] - 1
+		mencoes = append(mencoes, Mencao{
+			Nick:    nick,
+			Campo:   campo,
+			Inicio:  utf8.RuneCountInString(texto[:inicio]),
+			Tamanho: utf8.RuneCountInString(texto[inicio : indices[2]+len(nick)]),
+		})
+	}
+
+	return mencoes
+}
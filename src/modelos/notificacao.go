@@ -0,0 +1,98 @@
+package modelos
+
+import (
+	"fmt"
+	"time"
+)
+
+// Tipos de notificação
+const (
+	NotificacaoSeguidor          = "seguidor"
+	NotificacaoCurtida           = "curtida"
+	NotificacaoMencao            = "mencao"
+	NotificacaoRepostagem        = "repostagem"
+	NotificacaoCitacao           = "citacao"
+	NotificacaoComentario        = "comentario"
+	NotificacaoSolicitacao       = "solicitacao"
+	NotificacaoSolicitacaoAceita = "solicitacao_aceita"
+)
+
+// TiposNotificacao lista os tipos de notificação aceitos nas preferências
+var TiposNotificacao = []string{NotificacaoSeguidor, NotificacaoCurtida, NotificacaoMencao,
+	NotificacaoRepostagem, NotificacaoCitacao, NotificacaoComentario, NotificacaoSolicitacao,
+	NotificacaoSolicitacaoAceita}
+
+// Notificacao representa um grupo de notificações do mesmo tipo sobre o mesmo alvo
+type Notificacao struct {
+	ID           uint64    `json:"id,omitempty"`
+	Tipo         string    `json:"tipo,omitempty"`
+	PublicacaoID uint64    `json:"publicacaoId,omitempty"`
+	AtorID       uint64    `json:"atorId,omitempty"`
+	AtorNick     string    `json:"atorNick,omitempty"`
+	Total        uint64    `json:"total,omitempty"`
+	Lida         bool      `json:"lida"`
+	Mensagem     string    `json:"mensagem,omitempty"`
+	CriadaEm     time.Time `json:"criadaEm,omitempty"`
+}
+
+// FormatarMensagem monta o texto da notificação, agregando os demais atores
+func (notificacao *Notificacao) FormatarMensagem() {
+	atores := notificacao.AtorNick
+	if notificacao.Total > 1 {
+		atores = fmt.Sprintf("%s e mais %d", notificacao.AtorNick, notificacao.Total-1)
+	}
+
+	switch notificacao.Tipo {
+	case NotificacaoSeguidor:
+		notificacao.Mensagem = fmt.Sprintf("%s começou a seguir você", atores)
+		if notificacao.Total > 1 {
+			notificacao.Mensagem = fmt.Sprintf("%s começaram a seguir você", atores)
+		}
+	case NotificacaoCurtida:
+		notificacao.Mensagem = fmt.Sprintf("%s curtiu sua publicação", atores)
+		if notificacao.Total > 1 {
+			notificacao.Mensagem = fmt.Sprintf("%s curtiram sua publicação", atores)
+		}
+	case NotificacaoMencao:
+		notificacao.Mensagem = fmt.Sprintf("%s mencionou você em uma publicação", atores)
+		if notificacao.Total > 1 {
+			notificacao.Mensagem = fmt.Sprintf("%s mencionaram você em uma publicação", atores)
+		}
+	case NotificacaoRepostagem:
+		notificacao.Mensagem = fmt.Sprintf("%s repostou sua publicação", atores)
+		if notificacao.Total > 1 {
+			notificacao.Mensagem = fmt.Sprintf("%s repostaram sua publicação", atores)
+		}
+	case NotificacaoCitacao:
+		notificacao.Mensagem = fmt.Sprintf("%s citou sua publicação", atores)
+		if notificacao.Total > 1 {
+			notificacao.Mensagem = fmt.Sprintf("%s citaram sua publicação", atores)
+		}
+	case NotificacaoComentario:
+		notificacao.Mensagem = fmt.Sprintf("%s comentou sua publicação", atores)
+		if notificacao.Total > 1 {
+			notificacao.Mensagem = fmt.Sprintf("%s comentaram sua publicação", atores)
+		}
+	case NotificacaoSolicitacao:
+		notificacao.Mensagem = fmt.Sprintf("%s pediu para seguir você", atores)
+		if notificacao.Total > 1 {
+			notificacao.Mensagem = fmt.Sprintf("%s pediram para seguir você", atores)
+		}
+	case NotificacaoSolicitacaoAceita:
+		notificacao.Mensagem = fmt.Sprintf("%s aceitou seu pedido para seguir", atores)
+		if notificacao.Total > 1 {
+			notificacao.Mensagem = fmt.Sprintf("%s aceitaram seu pedido para seguir", atores)
+		}
+	}
+}
+
+// TipoNotificacaoValido indica se o tipo informado existe
+func TipoNotificacaoValido(tipo string) bool {
+	for _, t := range TiposNotificacao {
+		if t == tipo {
+			return true
+		}
+	}
+
+	return false
+}
@@ -2,18 +2,74 @@ package modelos
 
 import (
 	"errors"
+	"regexp"
 	"strings"
 	"time"
 )
 
+// Situações de uma publicação
+const (
+	StatusRascunho  = "rascunho"
+	StatusAgendada  = "agendada"
+	StatusPublicada = "publicada"
+)
+
+// MaximoPublicacoesFixadas é quantas publicações um usuário pode fixar no perfil
+const MaximoPublicacoesFixadas = 3
+
+// Visibilidades de uma publicação
+const (
+	VisibilidadePublico     = "publico"
+	VisibilidadeSeguidores  = "seguidores"
+	VisibilidadeMencionados = "mencionados"
+	VisibilidadePrivado     = "privado"
+)
+
+var (
+	regexTag     = regexp.MustCompile(`#([\p{L}\p{N}_]+)`)
+	regexNomeTag = regexp.MustCompile(`^[\p{L}\p{N}_]+$`)
+)
+
 type Publicacao struct {
-	ID        uint64    `json:"id,omitempty"`
-	Titulo    string    `json:"titulo,omitempty"`
-	Conteudo  string    `json:"conteudo,omitempty"`
-	AutorID   uint64    `json:"autorId,omitempty"`
-	AutorNick string    `json:"autorNick,omitempty"`
-	Curtidas  uint64    `json:"curtidas"`
-	CriadaEm  time.Time `json:"criadaEm,omitempty"`
+	ID           uint64            `json:"id,omitempty"`
+	Titulo       string            `json:"titulo,omitempty"`
+	Conteudo     string            `json:"conteudo,omitempty"`
+	AutorID      uint64            `json:"autorId,omitempty"`
+	AutorNick    string            `json:"autorNick,omitempty"`
+	Curtidas     uint64            `json:"curtidas"`
+	Reacoes      map[string]uint64 `json:"reacoes,omitempty"`
+	MinhaReacao  string            `json:"minhaReacao,omitempty"`
+	Repostagens  uint64            `json:"repostagens"`
+	Citacoes     uint64            `json:"citacoes"`
+	Comentarios  uint64            `json:"comentarios"`
+	CriadaEm     time.Time         `json:"criadaEm,omitempty"`
+	EditadaEm    *time.Time        `json:"editadaEm,omitempty"`
+	Editada      bool              `json:"editada"`
+	Status       string            `json:"status,omitempty"`
+	Visibilidade string            `json:"visibilidade,omitempty"`
+	PublicarEm   *time.Time        `json:"publicarEm,omitempty"`
+	ComunidadeID uint64            `json:"comunidadeId,omitempty"`
+	Tags         []string          `json:"tags,omitempty"`
+	Mencoes      []Mencao          `json:"mencoes,omitempty"`
+	SalvoPorMim  bool              `json:"salvoPorMim"`
+	Fixada       bool              `json:"fixada"`
+	Enquete      *Enquete          `json:"enquete,omitempty"`
+
+	// CitacaoID é a publicação citada por esta. Se ela tiver sido excluída ou deixado de ser
+	// pública, Citacao fica vazia e CitacaoIndisponivel indica que o original não pode ser exibido
+	CitacaoID           uint64      `json:"citacaoId,omitempty"`
+	Citacao             *Publicacao `json:"citacao,omitempty"`
+	CitacaoIndisponivel bool        `json:"citacaoIndisponivel,omitempty"`
+
+	// Repostagem é preenchida quando a publicação aparece no feed porque alguém a repostou
+	Repostagem *Repostagem `json:"repostagem,omitempty"`
+}
+
+// Repostagem representa o compartilhamento de uma publicação por outro usuário
+type Repostagem struct {
+	UsuarioID   uint64    `json:"usuarioId"`
+	UsuarioNick string    `json:"usuarioNick"`
+	CriadaEm    time.Time `json:"criadaEm"`
 }
 
 // Preparar ajusta uma piblicacao para os padrões corretos
@@ -22,7 +78,15 @@ func (publicacao *Publicacao) Preparar() error {
 		return erro
 	}
 
+	if publicacao.Enquete != nil {
+		if erro := publicacao.Enquete.Preparar(); erro != nil {
+			return erro
+		}
+	}
+
 	publicacao.formatar()
+	publicacao.extrairTags()
+	publicacao.Mencoes = append(extrairMencoes("titulo", publicacao.Titulo), extrairMencoes("conteudo", publicacao.Conteudo)...)
 	return nil
 }
 
@@ -35,10 +99,66 @@ func (publicacao *Publicacao) validar() error {
 		return errors.New("conteudo é obrigatório e nao pode estar em branco")
 	}
 
+	switch publicacao.Status {
+	case "":
+		publicacao.Status = StatusPublicada
+		publicacao.PublicarEm = nil
+	case StatusRascunho, StatusPublicada:
+		publicacao.PublicarEm = nil
+	case StatusAgendada:
+		if publicacao.PublicarEm == nil || !publicacao.PublicarEm.After(time.Now()) {
+			return errors.New("publicarEm é obrigatório e deve ser uma data futura")
+		}
+	default:
+		return errors.New("status inválido")
+	}
+
+	if publicacao.Visibilidade != "" && !VisibilidadeValida(publicacao.Visibilidade) {
+		return errors.New("visibilidade inválida")
+	}
+
 	return nil
 }
 
+// VisibilidadeValida indica se o valor é uma das visibilidades de publicação
+func VisibilidadeValida(visibilidade string) bool {
+	switch visibilidade {
+	case VisibilidadePublico, VisibilidadeSeguidores, VisibilidadeMencionados, VisibilidadePrivado:
+		return true
+	}
+
+	return false
+}
+
 func (publicacao *Publicacao) formatar() {
 	publicacao.Titulo = strings.TrimSpace(publicacao.Titulo)
 	publicacao.Conteudo = strings.TrimSpace(publicacao.Conteudo)
 }
+
+// extrairTags preenche as tags (#tag) encontradas no titulo e no conteudo
+func (publicacao *Publicacao) extrairTags() {
+	publicacao.Tags = nil
+	encontradas := map[string]bool{}
+
+	for _, texto := range []string{publicacao.Titulo, publicacao.Conteudo} {
+		for _, ocorrencia := range regexTag.FindAllStringSubmatch(texto, -1) {
+			tag := NormalizarTag(ocorrencia[1])
+			if tag == "" || encontradas[tag] {
+				continue
+			}
+
+			encontradas[tag] = true
+			publicacao.Tags = append(publicacao.Tags, tag)
+		}
+	}
+}
+
+// NormalizarTag deixa uma tag no formato salvo no banco (sem # e em minusculo)
+func NormalizarTag(tag string) string {
+	tag = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+	if !regexNomeTag.MatchString(tag) || len([]rune(tag)) > 50 {
+		return ""
+	}
+
+	return tag
+}
@@ -0,0 +1,11 @@
+package modelos
+
+import "time"
+
+// Reacao representa a reação de um usuário a uma publicação
+type Reacao struct {
+	Tipo        string    `json:"tipo"`
+	UsuarioID   uint64    `json:"usuarioId"`
+	UsuarioNick string    `json:"usuarioNick"`
+	CriadaEm    time.Time `json:"criadaEm"`
+}
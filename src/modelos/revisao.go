@@ -0,0 +1,99 @@
+package modelos
+
+import (
+	"regexp"
+	"time"
+)
+
+// Tipos de trecho de uma diferença entre versões
+const (
+	TrechoIgual      = "igual"
+	TrechoAdicionado = "adicionado"
+	TrechoRemovido   = "removido"
+)
+
+var regexPalavra = regexp.MustCompile(`\s+|[^\s]+`)
+
+// Revisao representa uma versão de uma publicação e o que mudou em relação à anterior
+type Revisao struct {
+	Versao             uint64    `json:"versao"`
+	Titulo             string    `json:"titulo"`
+	Conteudo           string    `json:"conteudo"`
+	CriadaEm           time.Time `json:"criadaEm"`
+	DiferencasTitulo   []Trecho  `json:"diferencasTitulo,omitempty"`
+	DiferencasConteudo []Trecho  `json:"diferencasConteudo,omitempty"`
+}
+
+// Trecho representa uma parte do texto que se manteve, foi adicionada ou removida entre versões
+type Trecho struct {
+	Tipo  string `json:"tipo"`
+	Texto string `json:"texto"`
+}
+
+// CompararRevisoes preenche as diferenças de cada versão em relação à anterior.
+// As revisões devem estar da mais antiga para a mais recente
+func CompararRevisoes(revisoes []Revisao) {
+	for i := 1; i < len(revisoes); i++ {
+		revisoes[i].DiferencasTitulo = Comparar(revisoes[i-1].Titulo, revisoes[i].Titulo)
+		revisoes[i].DiferencasConteudo = Comparar(revisoes[i-1].Conteudo, revisoes[i].Conteudo)
+	}
+}
+
+// Comparar retorna as diferenças palavra a palavra entre dois textos
+func Comparar(antigo, novo string) []Trecho {
+	a := regexPalavra.FindAllString(antigo, -1)
+	b := regexPalavra.FindAllString(novo, -1)
+
+	// comuns[i][j] é o tamanho da maior subsequência comum entre a[i:] e b[j:]
+	comuns := make([][]int, len(a)+1)
+	for i := range comuns {
+		comuns[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				comuns[i][j] = comuns[i+1][j+1] + 1
+			} else if comuns[i+1][j] >= comuns[i][j+1] {
+				comuns[i][j] = comuns[i+1][j]
+			} else {
+				comuns[i][j] = comuns[i][j+1]
+			}
+		}
+	}
+
+	var trechos []Trecho
+	adicionar := func(tipo, texto string) {
+		if n := len(trechos); n > 0 && trechos[n-1].Tipo == tipo {
+			trechos[n-1].Texto += texto
+			return
+		}
+		trechos = append(trechos, Trecho{Tipo: tipo, Texto: texto})
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			adicionar(TrechoIgual, a[i])
+			i++
+			j++
+		case comuns[i+1][j] >= comuns[i][j+1]:
+			adicionar(TrechoRemovido, a[i])
+			i++
+		default:
+			adicionar(TrechoAdicionado, b[j])
+			j++
+		}
+	}
+
+	for ; i < len(a); i++ {
+		adicionar(TrechoRemovido, a[i])
+	}
+
+	for ; j < len(b); j++ {
+		adicionar(TrechoAdicionado, b[j])
+	}
+
+	return trechos
+}
@@ -0,0 +1,33 @@
+package modelos
+
+import "fmt"
+
+// Sugestao representa um usuário sugerido para ser seguido e o motivo da sugestão
+type Sugestao struct {
+	Usuario       UsuarioResumido `json:"usuario"`
+	Motivo        string          `json:"motivo"`
+	AmigosEmComum uint64          `json:"amigosEmComum"`
+	TagsEmComum   uint64          `json:"tagsEmComum"`
+	Seguidores    uint64          `json:"seguidores"`
+
+	// AmigoNick é um dos usuários seguidos pelo leitor que segue o sugerido
+	AmigoNick string `json:"-"`
+}
+
+// FormatarMotivo monta o texto que explica a sugestão, priorizando quem o leitor já segue
+func (sugestao *Sugestao) FormatarMotivo() {
+	switch {
+	case sugestao.AmigosEmComum > 0 && sugestao.AmigoNick != "":
+		sugestao.Motivo = fmt.Sprintf("seguido por %s", sugestao.AmigoNick)
+		if sugestao.AmigosEmComum > 1 {
+			sugestao.Motivo = fmt.Sprintf("seguido por %s e mais %d", sugestao.AmigoNick, sugestao.AmigosEmComum-1)
+		}
+	case sugestao.TagsEmComum > 0:
+		sugestao.Motivo = "segue uma tag que você também segue"
+		if sugestao.TagsEmComum > 1 {
+			sugestao.Motivo = fmt.Sprintf("segue %d tags que você também segue", sugestao.TagsEmComum)
+		}
+	default:
+		sugestao.Motivo = "popular no DevBook"
+	}
+}
@@ -0,0 +1,38 @@
+package modelos
+
+import "time"
+
+// Janelas de tempo em que as tendências são calculadas
+const (
+	JanelaUmaHora   = "1h"
+	JanelaUmDia     = "24h"
+	JanelaUmaSemana = "7d"
+	JanelaPadrao    = JanelaUmDia
+)
+
+// Janelas relaciona cada janela de tendências à sua duração
+var Janelas = map[string]time.Duration{
+	JanelaUmaHora:   time.Hour,
+	JanelaUmDia:     24 * time.Hour,
+	JanelaUmaSemana: 7 * 24 * time.Hour,
+}
+
+// Tendencias reúne as publicações e tags em alta em uma janela de tempo
+type Tendencias struct {
+	Janela      string             `json:"janela"`
+	Publicacoes []PublicacaoEmAlta `json:"publicacoes,omitempty"`
+	Tags        []TagEmAlta        `json:"tags,omitempty"`
+}
+
+// PublicacaoEmAlta é uma publicação com a pontuação que a colocou nas tendências
+type PublicacaoEmAlta struct {
+	Publicacao Publicacao `json:"publicacao"`
+	Pontuacao  float64    `json:"pontuacao"`
+}
+
+// TagEmAlta é uma tag com a pontuação que a colocou nas tendências
+type TagEmAlta struct {
+	Nome        string  `json:"nome"`
+	Pontuacao   float64 `json:"pontuacao"`
+	Publicacoes uint64  `json:"publicacoes"`
+}
@@ -9,14 +9,67 @@ import (
 	"github.com/badoux/checkmail"
 )
 
+// Perfis de acesso de um usuário
+const (
+	PerfilUsuario       = "usuario"
+	PerfilModerador     = "moderador"
+	PerfilAdministrador = "administrador"
+)
+
+// PerfilAcima indica se o perfil do ator está acima do perfil do alvo, condição para que ele
+// possa aplicar sanções à conta ou às publicações do alvo
+func PerfilAcima(perfilAtor, perfilAlvo string) bool {
+	return nivelPerfil(perfilAtor) > nivelPerfil(perfilAlvo)
+}
+
+func nivelPerfil(perfil string) int {
+	switch perfil {
+	case PerfilAdministrador:
+		return 2
+	case PerfilModerador:
+		return 1
+	}
+
+	return 0
+}
+
 // Usuario representa um usuário no banco de dados
 type Usuario struct {
-	ID       uint64    `json:"id,omitempty"`
-	Nome     string    `json:"nome,omitempty"`
-	Nick     string    `json:"nick,omitempty"`
-	Email    string    `json:"email,omitempty"`
-	Senha    string    `json:"senha,omitempty"`
-	CriadoEm time.Time `json:"criadoEm,omitempty"`
+	ID                 uint64    `json:"id,omitempty"`
+	Nome               string    `json:"nome,omitempty"`
+	Nick               string    `json:"nick,omitempty"`
+	Email              string    `json:"email,omitempty"`
+	Senha              string    `json:"senha,omitempty"`
+	CriadoEm           time.Time `json:"criadoEm,omitempty"`
+	VisibilidadePadrao string    `json:"visibilidadePadrao,omitempty"`
+	// ContaPrivada indica que novos seguidores precisam ser aprovados. Ao atualizar o usuário,
+	// fica como está se não for informada
+	ContaPrivada *bool `json:"contaPrivada,omitempty"`
+
+	// Seguidores e Seguindo só são preenchidos quando o perfil é buscado individualmente
+	Seguidores *uint64 `json:"seguidores,omitempty"`
+	Seguindo   *uint64 `json:"seguindo,omitempty"`
+}
+
+// UsuarioResumido traz apenas o necessário para exibir um usuário em listas e sugestões
+type UsuarioResumido struct {
+	ID   uint64 `json:"id"`
+	Nome string `json:"nome"`
+	Nick string `json:"nick"`
+}
+
+// Relacao descreve como o leitor e outro usuário se relacionam. Pendente e PendentePor indicam
+// pedidos para seguir que aguardam aprovação; Silenciou só considera o leitor, já que ninguém
+// fica sabendo que foi silenciado
+type Relacao struct {
+	UsuarioID    uint64 `json:"usuarioId"`
+	Segue        bool   `json:"segue"`
+	SeguidoPor   bool   `json:"seguidoPor"`
+	Bloqueou     bool   `json:"bloqueou"`
+	BloqueadoPor bool   `json:"bloqueadoPor"`
+	Silenciou    bool   `json:"silenciou"`
+	Pendente     bool   `json:"pendente"`
+	PendentePor  bool   `json:"pendentePor"`
 }
 
 // Preparar chama os metodos para validar e formatar o usuario recebido
@@ -53,6 +106,10 @@ func (usuario *Usuario) validar(etapa string) error {
 		return errors.New("O campo senha é obrigatório e não pode estar em branco")
 	}
 
+	if usuario.VisibilidadePadrao != "" && !VisibilidadeValida(usuario.VisibilidadePadrao) {
+		return errors.New("O campo visibilidadePadrao é invalido")
+	}
+
 	return nil
 }
 
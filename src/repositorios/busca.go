@@ -0,0 +1,64 @@
+package repositorios
+
+import (
+	"api/src/busca"
+	"api/src/modelos"
+	"database/sql"
+)
+
+// ReconstruirIndice esvazia o buscador e indexa novamente todos os usuários e publicações
+// não excluídos. O buscador só é marcado como concluído se tudo for indexado. Retorna quantos
+// usuários e publicações foram indexados
+func ReconstruirIndice(db *sql.DB, buscador busca.Buscador) (uint64, uint64, error) {
+	buscador.Limpar()
+
+	linhas, erro := db.Query("select id, nome, nick from usuarios where deletadoEm is null")
+	if erro != nil {
+		return 0, 0, erro
+	}
+
+	defer linhas.Close()
+
+	var usuarios uint64
+	for linhas.Next() {
+		var usuario modelos.Usuario
+
+		if erro = linhas.Scan(&usuario.ID, &usuario.Nome, &usuario.Nick); erro != nil {
+			return 0, 0, erro
+		}
+
+		buscador.IndexarUsuario(usuario)
+		usuarios++
+	}
+
+	if erro = linhas.Err(); erro != nil {
+		return 0, 0, erro
+	}
+
+	linhasPublicacoes, erro := db.Query("select id, titulo, conteudo from publicacoes where deletadaEm is null")
+	if erro != nil {
+		return 0, 0, erro
+	}
+
+	defer linhasPublicacoes.Close()
+
+	var publicacoes uint64
+	for linhasPublicacoes.Next() {
+		var publicacao modelos.Publicacao
+
+		if erro = linhasPublicacoes.Scan(&publicacao.ID, &publicacao.Titulo, &publicacao.Conteudo); erro != nil {
+			return 0, 0, erro
+		}
+
+		buscador.IndexarPublicacao(publicacao)
+		publicacoes++
+	}
+
+	if erro = linhasPublicacoes.Err(); erro != nil {
+		return 0, 0, erro
+	}
+
+	buscador.Concluir()
+
+	return usuarios, publicacoes, nil
+}
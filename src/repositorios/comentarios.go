@@ -0,0 +1,106 @@
+package repositorios
+
+import (
+	"api/src/modelos"
+	"database/sql"
+)
+
+// RepositorioComentarios representa um repositorio de comentários em publicações
+type RepositorioComentarios struct {
+	db *sql.DB
+}
+
+// NovoRepositorioDeComentarios cria um repositorio de comentários
+func NovoRepositorioDeComentarios(db *sql.DB) *RepositorioComentarios {
+	return &RepositorioComentarios{db}
+}
+
+// Criar registra um comentário em uma publicação
+func (repo RepositorioComentarios) Criar(comentario modelos.Comentario) (uint64, error) {
+	statement, erro := repo.db.Prepare("insert into comentarios (publicacao_id, autor_id, conteudo) values (?, ?, ?)")
+	if erro != nil {
+		return 0, erro
+	}
+
+	defer statement.Close()
+
+	resultado, erro := statement.Exec(comentario.PublicacaoID, comentario.AutorID, comentario.Conteudo)
+	if erro != nil {
+		return 0, erro
+	}
+
+	ultimoIdInserido, erro := resultado.LastInsertId()
+	if erro != nil {
+		return 0, erro
+	}
+
+	return uint64(ultimoIdInserido), nil
+}
+
+// Buscar retorna os comentários de uma publicação, dos mais antigos para os mais recentes,
+// deixando de fora os de contas excluídas e os de usuários com bloqueio com o leitor
+func (repo RepositorioComentarios) Buscar(publicacaoID, leitorID, limite, deslocamento uint64) ([]modelos.Comentario, error) {
+	linhas, erro := repo.db.Query(`
+	select c.id, c.publicacao_id, c.autor_id, u.nick, c.conteudo, c.criadoEm from comentarios c
+	inner join usuarios u on u.id = c.autor_id
+	where c.publicacao_id = ? and u.deletadoEm is null
+	and not exists (
+		select 1 from bloqueios b
+		where (b.usuario_id = ? and b.bloqueado_id = c.autor_id)
+		or (b.usuario_id = c.autor_id and b.bloqueado_id = ?)
+	)
+	order by c.criadoEm, c.id
+	limit ? offset ?`, publicacaoID, leitorID, leitorID, limite, deslocamento)
+	if erro != nil {
+		return nil, erro
+	}
+
+	defer linhas.Close()
+
+	var comentarios []modelos.Comentario
+
+	for linhas.Next() {
+		var comentario modelos.Comentario
+
+		if erro = linhas.Scan(&comentario.ID, &comentario.PublicacaoID, &comentario.AutorID, &comentario.AutorNick,
+			&comentario.Conteudo, &comentario.CriadoEm); erro != nil {
+			return nil, erro
+		}
+
+		comentarios = append(comentarios, comentario)
+	}
+
+	return comentarios, linhas.Err()
+}
+
+// BuscarPorID retorna um comentário, ou um comentário vazio se ele não existir
+func (repo RepositorioComentarios) BuscarPorID(comentarioID uint64) (modelos.Comentario, error) {
+	var comentario modelos.Comentario
+
+	erro := repo.db.QueryRow(`
+	select c.id, c.publicacao_id, c.autor_id, u.nick, c.conteudo, c.criadoEm from comentarios c
+	inner join usuarios u on u.id = c.autor_id
+	where c.id = ?`, comentarioID).Scan(&comentario.ID, &comentario.PublicacaoID, &comentario.AutorID,
+		&comentario.AutorNick, &comentario.Conteudo, &comentario.CriadoEm)
+	if erro == sql.ErrNoRows {
+		return modelos.Comentario{}, nil
+	}
+
+	return comentario, erro
+}
+
+// Deletar apaga um comentário
+func (repo RepositorioComentarios) Deletar(comentarioID uint64) error {
+	statement, erro := repo.db.Prepare("delete from comentarios where id = ?")
+	if erro != nil {
+		return erro
+	}
+
+	defer statement.Close()
+
+	if _, erro = statement.Exec(comentarioID); erro != nil {
+		return erro
+	}
+
+	return nil
+}
@@ -0,0 +1,325 @@
+package repositorios
+
+import (
+	"api/src/busca"
+	"api/src/modelos"
+	"database/sql"
+	"fmt"
+)
+
+// RepositorioComunidades representa um repositorio de comunidades
+type RepositorioComunidades struct {
+	db *sql.DB
+}
+
+// NovoRepositorioDeComunidades cria um repositorio de comunidades
+func NovoRepositorioDeComunidades(db *sql.DB) *RepositorioComunidades {
+	return &RepositorioComunidades{db}
+}
+
+// Criar salva uma comunidade e registra o dono como membro
+func (repo RepositorioComunidades) Criar(comunidade modelos.Comunidade) (uint64, error) {
+	transacao, erro := repo.db.Begin()
+	if erro != nil {
+		return 0, erro
+	}
+
+	defer transacao.Rollback()
+
+	resultado, erro := transacao.Exec(
+		"insert into comunidades (nome, descricao, privada, dono_id) values (?, ?, ?, ?)",
+		comunidade.Nome, comunidade.Descricao, comunidade.Privada, comunidade.DonoID)
+	if erro != nil {
+		return 0, erro
+	}
+
+	comunidadeID, erro := resultado.LastInsertId()
+	if erro != nil {
+		return 0, erro
+	}
+
+	if _, erro = transacao.Exec(
+		"insert into comunidade_membros (comunidade_id, usuario_id, papel) values (?, ?, ?)",
+		comunidadeID, comunidade.DonoID, modelos.PapelDono); erro != nil {
+		return 0, erro
+	}
+
+	if erro = transacao.Commit(); erro != nil {
+		return 0, erro
+	}
+
+	return uint64(comunidadeID), nil
+}
+
+// Buscar retorna as comunidades públicas e as que o usuário participa, filtradas pelo nome
+func (repo RepositorioComunidades) Buscar(usuarioID uint64, nome string) ([]modelos.Comunidade, error) {
+	nome = fmt.Sprintf("%%%s%%", nome)
+
+	linhas, erro := repo.db.Query(`
+	select c.id, c.nome, c.descricao, c.privada, c.dono_id, c.criadaEm,
+	(select count(*) from comunidade_membros cm where cm.comunidade_id = c.id) as membros
+	from comunidades c
+	where c.nome like ?
+	and (c.privada = false or exists (
+		select 1 from comunidade_membros cm where cm.comunidade_id = c.id and cm.usuario_id = ?
+	))
+	order by c.nome`, nome, usuarioID)
+
+	if erro != nil {
+		return nil, erro
+	}
+
+	defer linhas.Close()
+
+	var comunidades []modelos.Comunidade
+
+	for linhas.Next() {
+		var comunidade modelos.Comunidade
+
+		if erro = linhas.Scan(&comunidade.ID, &comunidade.Nome, &comunidade.Descricao, &comunidade.Privada,
+			&comunidade.DonoID, &comunidade.CriadaEm, &comunidade.Membros); erro != nil {
+			return nil, erro
+		}
+
+		comunidades = append(comunidades, comunidade)
+	}
+
+	return comunidades, nil
+}
+
+// BuscarPorID retorna uma comunidade
+func (repo RepositorioComunidades) BuscarPorID(comunidadeID uint64) (modelos.Comunidade, error) {
+	var comunidade modelos.Comunidade
+	erro := repo.db.QueryRow(`
+	select c.id, c.nome, c.descricao, c.privada, c.dono_id, c.criadaEm,
+	(select count(*) from comunidade_membros cm where cm.comunidade_id = c.id) as membros
+	from comunidades c where c.id = ?`, comunidadeID).Scan(&comunidade.ID, &comunidade.Nome,
+		&comunidade.Descricao, &comunidade.Privada, &comunidade.DonoID, &comunidade.CriadaEm, &comunidade.Membros)
+
+	if erro == sql.ErrNoRows {
+		return modelos.Comunidade{}, nil
+	}
+
+	return comunidade, erro
+}
+
+// Atualizar altera nome, descrição e privacidade de uma comunidade
+func (repo RepositorioComunidades) Atualizar(comunidadeID uint64, comunidade modelos.Comunidade) error {
+	statement, erro := repo.db.Prepare("update comunidades set nome = ?, descricao = ?, privada = ? where id = ?")
+	if erro != nil {
+		return erro
+	}
+
+	defer statement.Close()
+
+	if _, erro = statement.Exec(comunidade.Nome, comunidade.Descricao, comunidade.Privada, comunidadeID); erro != nil {
+		return erro
+	}
+
+	return nil
+}
+
+// Deletar apaga uma comunidade. Suas publicações são excluídas como as demais, podendo ser
+// restauradas pelos autores dentro do prazo
+func (repo RepositorioComunidades) Deletar(comunidadeID uint64) error {
+	transacao, erro := repo.db.Begin()
+	if erro != nil {
+		return erro
+	}
+
+	defer transacao.Rollback()
+
+	linhas, erro := transacao.Query(
+		"select id from publicacoes where comunidade_id = ? and deletadaEm is null", comunidadeID)
+	if erro != nil {
+		return erro
+	}
+
+	var publicacoes []uint64
+	for linhas.Next() {
+		var publicacaoID uint64
+		if erro = linhas.Scan(&publicacaoID); erro != nil {
+			linhas.Close()
+			return erro
+		}
+
+		publicacoes = append(publicacoes, publicacaoID)
+	}
+	linhas.Close()
+
+	if erro = linhas.Err(); erro != nil {
+		return erro
+	}
+
+	// As publicações saem da comunidade como excluídas, dentro do prazo de restauração. As de
+	// comunidades privadas voltam como privadas, para não ficarem visíveis fora da comunidade
+	if _, erro = transacao.Exec(`
+	update publicacoes p
+	inner join comunidades c on c.id = p.comunidade_id
+	set p.deletadaEm = coalesce(p.deletadaEm, now()),
+	p.visibilidade = if(c.privada, 'privado', p.visibilidade)
+	where p.comunidade_id = ?`, comunidadeID); erro != nil {
+		return erro
+	}
+
+	if _, erro = transacao.Exec("delete from comunidades where id = ?", comunidadeID); erro != nil {
+		return erro
+	}
+
+	if erro = transacao.Commit(); erro != nil {
+		return erro
+	}
+
+	for _, publicacaoID := range publicacoes {
+		busca.Padrao.RemoverPublicacao(publicacaoID)
+	}
+
+	return nil
+}
+
+// BuscarPapel retorna o papel do usuário na comunidade, ou "" se ele não for membro
+func (repo RepositorioComunidades) BuscarPapel(comunidadeID, usuarioID uint64) (string, error) {
+	var papel string
+	erro := repo.db.QueryRow(
+		"select papel from comunidade_membros where comunidade_id = ? and usuario_id = ?",
+		comunidadeID, usuarioID).Scan(&papel)
+
+	if erro == sql.ErrNoRows {
+		return "", nil
+	}
+
+	return papel, erro
+}
+
+// Entrar adiciona um usuário como membro da comunidade, consumindo o convite se houver
+func (repo RepositorioComunidades) Entrar(comunidadeID, usuarioID uint64) error {
+	statement, erro := repo.db.Prepare("insert ignore into comunidade_membros (comunidade_id, usuario_id) values (?, ?)")
+	if erro != nil {
+		return erro
+	}
+
+	defer statement.Close()
+
+	if _, erro = statement.Exec(comunidadeID, usuarioID); erro != nil {
+		return erro
+	}
+
+	if _, erro = repo.db.Exec(
+		"delete from comunidade_convites where comunidade_id = ? and usuario_id = ?",
+		comunidadeID, usuarioID); erro != nil {
+		return erro
+	}
+
+	return nil
+}
+
+// RemoverMembro retira um usuário da comunidade
+func (repo RepositorioComunidades) RemoverMembro(comunidadeID, usuarioID uint64) error {
+	statement, erro := repo.db.Prepare("delete from comunidade_membros where comunidade_id = ? and usuario_id = ?")
+	if erro != nil {
+		return erro
+	}
+
+	defer statement.Close()
+
+	if _, erro = statement.Exec(comunidadeID, usuarioID); erro != nil {
+		return erro
+	}
+
+	return nil
+}
+
+// Convidar registra um convite para um usuário entrar na comunidade
+func (repo RepositorioComunidades) Convidar(comunidadeID, usuarioID, convidadoPor uint64) error {
+	statement, erro := repo.db.Prepare(
+		"insert ignore into comunidade_convites (comunidade_id, usuario_id, convidado_por) values (?, ?, ?)")
+	if erro != nil {
+		return erro
+	}
+
+	defer statement.Close()
+
+	if _, erro = statement.Exec(comunidadeID, usuarioID, convidadoPor); erro != nil {
+		return erro
+	}
+
+	return nil
+}
+
+// ExisteConvite indica se o usuário foi convidado para a comunidade
+func (repo RepositorioComunidades) ExisteConvite(comunidadeID, usuarioID uint64) (bool, error) {
+	var existe bool
+	erro := repo.db.QueryRow(
+		"select exists (select 1 from comunidade_convites where comunidade_id = ? and usuario_id = ?)",
+		comunidadeID, usuarioID).Scan(&existe)
+
+	return existe, erro
+}
+
+// DefinirPapel altera o papel de um membro da comunidade
+func (repo RepositorioComunidades) DefinirPapel(comunidadeID, usuarioID uint64, papel string) error {
+	statement, erro := repo.db.Prepare("update comunidade_membros set papel = ? where comunidade_id = ? and usuario_id = ?")
+	if erro != nil {
+		return erro
+	}
+
+	defer statement.Close()
+
+	if _, erro = statement.Exec(papel, comunidadeID, usuarioID); erro != nil {
+		return erro
+	}
+
+	return nil
+}
+
+// BuscarMembros retorna os membros de uma comunidade
+func (repo RepositorioComunidades) BuscarMembros(comunidadeID uint64) ([]modelos.MembroComunidade, error) {
+	linhas, erro := repo.db.Query(`
+	select u.id, u.nome, u.nick, cm.papel, cm.entrouEm from usuarios u
+	inner join comunidade_membros cm on cm.usuario_id = u.id
+	where cm.comunidade_id = ? and u.deletadoEm is null
+	order by field(cm.papel, 'dono', 'moderador', 'membro'), cm.entrouEm`, comunidadeID)
+
+	if erro != nil {
+		return nil, erro
+	}
+
+	defer linhas.Close()
+
+	var membros []modelos.MembroComunidade
+
+	for linhas.Next() {
+		var membro modelos.MembroComunidade
+
+		if erro = linhas.Scan(&membro.ID, &membro.Nome, &membro.Nick, &membro.Papel, &membro.EntrouEm); erro != nil {
+			return nil, erro
+		}
+
+		membros = append(membros, membro)
+	}
+
+	return membros, nil
+}
+
+// BuscarPublicacoes retorna as publicações de uma comunidade que o leitor pode ver
+func (repo RepositorioComunidades) BuscarPublicacoes(comunidadeID, leitorID uint64) ([]modelos.Publicacao, error) {
+	linhas, erro := repo.db.Query(`
+	select `+colunasPublicacao+` from publicacoes p
+	inner join usuarios u on u.id = p.autor_id
+	where p.comunidade_id = ? and `+filtroLeitura+`
+	order by p.criadaEm desc, p.id desc
+	`, comunidadeID, leitorID)
+
+	if erro != nil {
+		return nil, erro
+	}
+
+	defer linhas.Close()
+
+	publicacoes, erro := escanearPublicacoes(linhas)
+	if erro != nil {
+		return nil, erro
+	}
+
+	return publicacoes, completarPublicacoes(repo.db, publicacoes, leitorID)
+}
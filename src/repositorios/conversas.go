@@ -0,0 +1,317 @@
+package repositorios
+
+import (
+	"api/src/modelos"
+	"database/sql"
+	"sort"
+)
+
+// RepositorioConversas representa um repositorio de conversas e mensagens
+type RepositorioConversas struct {
+	db *sql.DB
+}
+
+// NovoRepositorioDeConversas cria um repositorio de conversas
+func NovoRepositorioDeConversas(db *sql.DB) *RepositorioConversas {
+	return &RepositorioConversas{db}
+}
+
+// Criar cria uma conversa com o criador e os participantes informados. Com mais de um
+// participante além do criador, a conversa é um grupo
+func (repo RepositorioConversas) Criar(criadorID uint64, participantes []uint64) (uint64, error) {
+	transacao, erro := repo.db.Begin()
+	if erro != nil {
+		return 0, erro
+	}
+
+	defer transacao.Rollback()
+
+	resultado, erro := transacao.Exec("insert into conversas (criador_id, grupo) values (?, ?)",
+		criadorID, len(participantes) > 1)
+	if erro != nil {
+		return 0, erro
+	}
+
+	conversaID, erro := resultado.LastInsertId()
+	if erro != nil {
+		return 0, erro
+	}
+
+	for _, usuarioID := range append([]uint64{criadorID}, participantes...) {
+		if _, erro = transacao.Exec(
+			"insert ignore into conversa_participantes (conversa_id, usuario_id) values (?, ?)",
+			conversaID, usuarioID); erro != nil {
+			return 0, erro
+		}
+	}
+
+	if erro = transacao.Commit(); erro != nil {
+		return 0, erro
+	}
+
+	return uint64(conversaID), nil
+}
+
+// BuscarConversaIndividual retorna o ID da conversa individual (que não é grupo) entre os dois usuários, ou 0
+func (repo RepositorioConversas) BuscarConversaIndividual(usuarioID, outroUsuarioID uint64) (uint64, error) {
+	var conversaID uint64
+	erro := repo.db.QueryRow(`
+	select c.id from conversas c
+	inner join conversa_participantes cp on cp.conversa_id = c.id and cp.usuario_id = ?
+	inner join conversa_participantes outro on outro.conversa_id = c.id and outro.usuario_id = ?
+	where c.grupo = false
+	order by c.id
+	limit 1`, usuarioID, outroUsuarioID).Scan(&conversaID)
+
+	if erro == sql.ErrNoRows {
+		return 0, nil
+	}
+
+	return conversaID, erro
+}
+
+// Buscar retorna as conversas de um usuário, das mais recentes para as mais antigas
+func (repo RepositorioConversas) Buscar(usuarioID, limite, deslocamento uint64) ([]modelos.Conversa, error) {
+	linhas, erro := repo.db.Query(`
+	select c.id, c.grupo, c.criadaEm,
+	(select count(*) from mensagens m
+		where m.conversa_id = c.id and m.id > cp.ultima_leitura_id and m.autor_id <> cp.usuario_id) as nao_lidas,
+	(select max(m.id) from mensagens m where m.conversa_id = c.id) as ultima_mensagem_id
+	from conversas c
+	inner join conversa_participantes cp on cp.conversa_id = c.id
+	where cp.usuario_id = ?
+	order by coalesce(ultima_mensagem_id, 0) desc, c.id desc
+	limit ? offset ?
+	`, usuarioID, limite, deslocamento)
+
+	if erro != nil {
+		return nil, erro
+	}
+
+	defer linhas.Close()
+
+	var conversas []modelos.Conversa
+	var ultimasMensagens []sql.NullInt64
+
+	for linhas.Next() {
+		var conversa modelos.Conversa
+		var ultimaMensagemID sql.NullInt64
+
+		if erro = linhas.Scan(&conversa.ID, &conversa.Grupo, &conversa.CriadaEm, &conversa.NaoLidas, &ultimaMensagemID); erro != nil {
+			return nil, erro
+		}
+
+		conversas = append(conversas, conversa)
+		ultimasMensagens = append(ultimasMensagens, ultimaMensagemID)
+	}
+
+	if erro = linhas.Err(); erro != nil {
+		return nil, erro
+	}
+
+	for i := range conversas {
+		if conversas[i].Participantes, erro = repo.BuscarParticipantes(conversas[i].ID); erro != nil {
+			return nil, erro
+		}
+
+		if !ultimasMensagens[i].Valid {
+			continue
+		}
+
+		mensagem, erro := repo.buscarMensagem(uint64(ultimasMensagens[i].Int64))
+		if erro != nil {
+			return nil, erro
+		}
+
+		conversas[i].UltimaMensagem = &mensagem
+	}
+
+	return conversas, nil
+}
+
+// BuscarParticipantes retorna os usuários que participam de uma conversa
+func (repo RepositorioConversas) BuscarParticipantes(conversaID uint64) ([]modelos.Usuario, error) {
+	linhas, erro := repo.db.Query(`
+	select u.id, u.nome, u.nick from usuarios u
+	inner join conversa_participantes cp on cp.usuario_id = u.id
+	where cp.conversa_id = ? and u.deletadoEm is null
+	order by cp.entrouEm, u.id`, conversaID)
+
+	if erro != nil {
+		return nil, erro
+	}
+
+	defer linhas.Close()
+
+	var usuarios []modelos.Usuario
+
+	for linhas.Next() {
+		var usuario modelos.Usuario
+
+		if erro = linhas.Scan(&usuario.ID, &usuario.Nome, &usuario.Nick); erro != nil {
+			return nil, erro
+		}
+
+		usuarios = append(usuarios, usuario)
+	}
+
+	return usuarios, nil
+}
+
+// EhParticipante indica se um usuário participa de uma conversa
+func (repo RepositorioConversas) EhParticipante(conversaID, usuarioID uint64) (bool, error) {
+	var participa bool
+	erro := repo.db.QueryRow(
+		"select exists (select 1 from conversa_participantes where conversa_id = ? and usuario_id = ?)",
+		conversaID, usuarioID).Scan(&participa)
+
+	return participa, erro
+}
+
+// CriarMensagem salva uma mensagem e a marca como lida pelo autor
+func (repo RepositorioConversas) CriarMensagem(mensagem modelos.Mensagem) (uint64, error) {
+	statement, erro := repo.db.Prepare("insert into mensagens (conversa_id, autor_id, conteudo) values (?, ?, ?)")
+	if erro != nil {
+		return 0, erro
+	}
+
+	defer statement.Close()
+
+	resultado, erro := statement.Exec(mensagem.ConversaID, mensagem.AutorID, mensagem.Conteudo)
+	if erro != nil {
+		return 0, erro
+	}
+
+	ultimoIdInserido, erro := resultado.LastInsertId()
+	if erro != nil {
+		return 0, erro
+	}
+
+	if erro = repo.MarcarComoLida(mensagem.ConversaID, mensagem.AutorID); erro != nil {
+		return 0, erro
+	}
+
+	return uint64(ultimoIdInserido), nil
+}
+
+// BuscarMensagens retorna as mensagens de uma conversa, das mais recentes para as mais antigas,
+// com os participantes que já as leram
+func (repo RepositorioConversas) BuscarMensagens(conversaID, limite, deslocamento uint64) ([]modelos.Mensagem, error) {
+	linhas, erro := repo.db.Query(`
+	select m.id, m.conversa_id, m.autor_id, u.nick, m.conteudo, m.criadaEm from mensagens m
+	inner join usuarios u on u.id = m.autor_id
+	where m.conversa_id = ? and u.deletadoEm is null
+	order by m.id desc
+	limit ? offset ?
+	`, conversaID, limite, deslocamento)
+
+	if erro != nil {
+		return nil, erro
+	}
+
+	defer linhas.Close()
+
+	var mensagens []modelos.Mensagem
+
+	for linhas.Next() {
+		var mensagem modelos.Mensagem
+
+		if erro = linhas.Scan(&mensagem.ID, &mensagem.ConversaID, &mensagem.AutorID, &mensagem.AutorNick,
+			&mensagem.Conteudo, &mensagem.CriadaEm); erro != nil {
+			return nil, erro
+		}
+
+		mensagens = append(mensagens, mensagem)
+	}
+
+	if erro = linhas.Err(); erro != nil {
+		return nil, erro
+	}
+
+	leituras, erro := repo.buscarLeituras(conversaID)
+	if erro != nil {
+		return nil, erro
+	}
+
+	for i := range mensagens {
+		for usuarioID, ultimaLeituraID := range leituras {
+			if usuarioID != mensagens[i].AutorID && ultimaLeituraID >= mensagens[i].ID {
+				mensagens[i].LidaPor = append(mensagens[i].LidaPor, usuarioID)
+			}
+		}
+
+		sort.Slice(mensagens[i].LidaPor, func(a, b int) bool { return mensagens[i].LidaPor[a] < mensagens[i].LidaPor[b] })
+	}
+
+	return mensagens, nil
+}
+
+// MarcarComoLida registra que o usuário leu todas as mensagens atuais da conversa
+func (repo RepositorioConversas) MarcarComoLida(conversaID, usuarioID uint64) error {
+	statement, erro := repo.db.Prepare(`
+	update conversa_participantes
+	set ultima_leitura_id = (select coalesce(max(id), 0) from mensagens where conversa_id = ?)
+	where conversa_id = ? and usuario_id = ?`)
+	if erro != nil {
+		return erro
+	}
+
+	defer statement.Close()
+
+	if _, erro = statement.Exec(conversaID, conversaID, usuarioID); erro != nil {
+		return erro
+	}
+
+	return nil
+}
+
+// Sair remove um usuário de uma conversa
+func (repo RepositorioConversas) Sair(conversaID, usuarioID uint64) error {
+	statement, erro := repo.db.Prepare("delete from conversa_participantes where conversa_id = ? and usuario_id = ?")
+	if erro != nil {
+		return erro
+	}
+
+	defer statement.Close()
+
+	if _, erro = statement.Exec(conversaID, usuarioID); erro != nil {
+		return erro
+	}
+
+	return nil
+}
+
+func (repo RepositorioConversas) buscarMensagem(mensagemID uint64) (modelos.Mensagem, error) {
+	var mensagem modelos.Mensagem
+	erro := repo.db.QueryRow(`
+	select m.id, m.conversa_id, m.autor_id, u.nick, m.conteudo, m.criadaEm from mensagens m
+	inner join usuarios u on u.id = m.autor_id
+	where m.id = ?`, mensagemID).Scan(&mensagem.ID, &mensagem.ConversaID, &mensagem.AutorID,
+		&mensagem.AutorNick, &mensagem.Conteudo, &mensagem.CriadaEm)
+
+	return mensagem, erro
+}
+
+func (repo RepositorioConversas) buscarLeituras(conversaID uint64) (map[uint64]uint64, error) {
+	linhas, erro := repo.db.Query(
+		"select usuario_id, ultima_leitura_id from conversa_participantes where conversa_id = ?", conversaID)
+	if erro != nil {
+		return nil, erro
+	}
+
+	defer linhas.Close()
+
+	leituras := make(map[uint64]uint64)
+
+	for linhas.Next() {
+		var usuarioID, ultimaLeituraID uint64
+
+		if erro = linhas.Scan(&usuarioID, &ultimaLeituraID); erro != nil {
+			return nil, erro
+		}
+
+		leituras[usuarioID] = ultimaLeituraID
+	}
+
+	return leituras, nil
+}
@@ -0,0 +1,242 @@
+package repositorios
+
+import (
+	"api/src/modelos"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// RepositorioDenuncias representa um repositorio de denúncias e decisões de moderação
+type RepositorioDenuncias struct {
+	db *sql.DB
+}
+
+// NovoRepositorioDeDenuncias cria um repositorio de denúncias
+func NovoRepositorioDeDenuncias(db *sql.DB) *RepositorioDenuncias {
+	return &RepositorioDenuncias{db}
+}
+
+// Criar salva uma denúncia no banco de dados
+func (repo RepositorioDenuncias) Criar(denuncia modelos.Denuncia) (uint64, error) {
+	statement, erro := repo.db.Prepare(`
+	insert into denuncias (denunciante_id, usuario_id, publicacao_id, motivo, descricao)
+	values (?, ?, nullif(?, 0), ?, ?)`)
+	if erro != nil {
+		return 0, erro
+	}
+
+	defer statement.Close()
+
+	resultado, erro := statement.Exec(denuncia.DenuncianteID, denuncia.UsuarioID, denuncia.PublicacaoID,
+		denuncia.Motivo, denuncia.Descricao)
+	if erro != nil {
+		return 0, erro
+	}
+
+	ultimoIdInserido, erro := resultado.LastInsertId()
+	if erro != nil {
+		return 0, erro
+	}
+
+	return uint64(ultimoIdInserido), nil
+}
+
+// Buscar retorna as denúncias com a situação informada (ou todas), das mais antigas para as mais recentes
+func (repo RepositorioDenuncias) Buscar(status string, limite, deslocamento uint64) ([]modelos.Denuncia, error) {
+	linhas, erro := repo.db.Query(`
+	select `+colunasDenuncia+` from denuncias
+	where ? = '' or status = ?
+	order by id
+	limit ? offset ?`, status, status, limite, deslocamento)
+
+	if erro != nil {
+		return nil, erro
+	}
+
+	defer linhas.Close()
+
+	var denuncias []modelos.Denuncia
+
+	for linhas.Next() {
+		denuncia, erro := escanearDenuncia(linhas)
+		if erro != nil {
+			return nil, erro
+		}
+
+		denuncias = append(denuncias, denuncia)
+	}
+
+	return denuncias, nil
+}
+
+// BuscarPorID retorna uma denúncia
+func (repo RepositorioDenuncias) BuscarPorID(denunciaID uint64) (modelos.Denuncia, error) {
+	linhas, erro := repo.db.Query("select "+colunasDenuncia+" from denuncias where id = ?", denunciaID)
+	if erro != nil {
+		return modelos.Denuncia{}, erro
+	}
+
+	defer linhas.Close()
+
+	if linhas.Next() {
+		return escanearDenuncia(linhas)
+	}
+
+	return modelos.Denuncia{}, nil
+}
+
+// Atribuir coloca uma denúncia em análise por um moderador
+func (repo RepositorioDenuncias) Atribuir(denunciaID, moderadorID uint64) error {
+	statement, erro := repo.db.Prepare(`
+	update denuncias set moderador_id = ?, status = ?
+	where id = ? and status <> ?`)
+	if erro != nil {
+		return erro
+	}
+
+	defer statement.Close()
+
+	if _, erro = statement.Exec(moderadorID, modelos.DenunciaEmAnalise, denunciaID, modelos.DenunciaResolvida); erro != nil {
+		return erro
+	}
+
+	return nil
+}
+
+// ErrContaBanida indica uma tentativa de suspender uma conta que já está banida
+var ErrContaBanida = errors.New("a conta está banida e não pode ser suspensa")
+
+// Resolver registra a decisão do moderador, aplica a ação escolhida e encerra a denúncia
+func (repo RepositorioDenuncias) Resolver(denuncia modelos.Denuncia, decisao modelos.DecisaoModeracao) (uint64, error) {
+	transacao, erro := repo.db.Begin()
+	if erro != nil {
+		return 0, erro
+	}
+
+	defer transacao.Rollback()
+
+	resultado, erro := transacao.Exec(`
+	update denuncias set status = ?, moderador_id = ?, resolvidaEm = now()
+	where id = ? and status <> ?`,
+		modelos.DenunciaResolvida, decisao.ModeradorID, denuncia.ID, modelos.DenunciaResolvida)
+	if erro != nil {
+		return 0, erro
+	}
+
+	if linhasAfetadas, erro := resultado.RowsAffected(); erro != nil || linhasAfetadas == 0 {
+		if erro == nil {
+			erro = errors.New("denúncia já resolvida")
+		}
+		return 0, erro
+	}
+
+	switch decisao.Acao {
+	case modelos.AcaoOcultarPublicacao:
+		_, erro = transacao.Exec("update publicacoes set oculta = true where id = ?", denuncia.PublicacaoID)
+	case modelos.AcaoSuspenderUsuario:
+		erro = suspender(transacao, denuncia, decisao)
+	}
+	if erro != nil {
+		return 0, erro
+	}
+
+	resultado, erro = transacao.Exec(`
+	insert into decisoes_moderacao (denuncia_id, moderador_id, acao, observacao, dias_suspensao)
+	values (?, ?, ?, ?, ?)`,
+		denuncia.ID, decisao.ModeradorID, decisao.Acao, decisao.Observacao, decisao.DiasSuspensao)
+	if erro != nil {
+		return 0, erro
+	}
+
+	decisaoID, erro := resultado.LastInsertId()
+	if erro != nil {
+		return 0, erro
+	}
+
+	if erro = transacao.Commit(); erro != nil {
+		return 0, erro
+	}
+
+	return uint64(decisaoID), nil
+}
+
+// suspender suspende o usuário denunciado pelos dias da decisão. Uma conta banida não pode ser
+// suspensa e uma suspensão em vigor nunca é encurtada: se ela terminar depois, fica como está
+func suspender(transacao *sql.Tx, denuncia modelos.Denuncia, decisao modelos.DecisaoModeracao) error {
+	var estado string
+	var suspensoAteAtual sql.NullTime
+
+	if erro := transacao.QueryRow("select estado, suspenso_ate from usuarios where id = ? for update",
+		denuncia.UsuarioID).Scan(&estado, &suspensoAteAtual); erro != nil {
+		return erro
+	}
+
+	if estado == modelos.EstadoBanido {
+		return ErrContaBanida
+	}
+
+	suspensoAte := time.Now().AddDate(0, 0, int(decisao.DiasSuspensao))
+	if estado == modelos.EstadoSuspenso && suspensoAteAtual.Valid && !suspensoAteAtual.Time.Before(suspensoAte) {
+		return nil
+	}
+
+	return alterarEstado(transacao, denuncia.UsuarioID, modelos.EstadoConta{
+		Estado:      modelos.EstadoSuspenso,
+		SuspensoAte: &suspensoAte,
+		Motivo:      fmt.Sprintf("denúncia %d: %s", denuncia.ID, decisao.Observacao),
+		AlteradoPor: decisao.ModeradorID,
+	})
+}
+
+// BuscarDecisoes retorna o histórico de decisões de moderação, opcionalmente de uma denúncia
+func (repo RepositorioDenuncias) BuscarDecisoes(denunciaID, limite, deslocamento uint64) ([]modelos.DecisaoModeracao, error) {
+	linhas, erro := repo.db.Query(`
+	select id, denuncia_id, moderador_id, acao, observacao, dias_suspensao, criadaEm
+	from decisoes_moderacao
+	where ? = 0 or denuncia_id = ?
+	order by id desc
+	limit ? offset ?`, denunciaID, denunciaID, limite, deslocamento)
+
+	if erro != nil {
+		return nil, erro
+	}
+
+	defer linhas.Close()
+
+	var decisoes []modelos.DecisaoModeracao
+
+	for linhas.Next() {
+		var decisao modelos.DecisaoModeracao
+
+		if erro = linhas.Scan(&decisao.ID, &decisao.DenunciaID, &decisao.ModeradorID, &decisao.Acao,
+			&decisao.Observacao, &decisao.DiasSuspensao, &decisao.CriadaEm); erro != nil {
+			return nil, erro
+		}
+
+		decisoes = append(decisoes, decisao)
+	}
+
+	return decisoes, nil
+}
+
+const colunasDenuncia = `id, coalesce(denunciante_id, 0), coalesce(usuario_id, 0), coalesce(publicacao_id, 0),
+	motivo, descricao, status, coalesce(moderador_id, 0), criadaEm, resolvidaEm`
+
+func escanearDenuncia(linhas *sql.Rows) (modelos.Denuncia, error) {
+	var denuncia modelos.Denuncia
+	var resolvidaEm sql.NullTime
+
+	if erro := linhas.Scan(&denuncia.ID, &denuncia.DenuncianteID, &denuncia.UsuarioID, &denuncia.PublicacaoID,
+		&denuncia.Motivo, &denuncia.Descricao, &denuncia.Status, &denuncia.ModeradorID, &denuncia.CriadaEm,
+		&resolvidaEm); erro != nil {
+		return modelos.Denuncia{}, erro
+	}
+
+	if resolvidaEm.Valid {
+		denuncia.ResolvidaEm = &resolvidaEm.Time
+	}
+
+	return denuncia, nil
+}
@@ -0,0 +1,267 @@
+package repositorios
+
+import (
+	"api/src/modelos"
+	"database/sql"
+)
+
+// RepositorioEnquetes representa um repositorio de enquetes
+type RepositorioEnquetes struct {
+	db *sql.DB
+}
+
+// NovoRepositorioDeEnquetes cria um repositorio de enquetes
+func NovoRepositorioDeEnquetes(db *sql.DB) *RepositorioEnquetes {
+	return &RepositorioEnquetes{db}
+}
+
+// Criar salva a enquete de uma publicação com suas opções
+func (repo RepositorioEnquetes) Criar(publicacaoID uint64, enquete modelos.Enquete) error {
+	transacao, erro := repo.db.Begin()
+	if erro != nil {
+		return erro
+	}
+
+	defer transacao.Rollback()
+
+	if _, erro = transacao.Exec(`
+	insert into enquetes (publicacao_id, multipla, encerraEm, ocultar_resultados) values (?, ?, ?, ?)`,
+		publicacaoID, enquete.Multipla, enquete.EncerraEm, enquete.OcultarResultados); erro != nil {
+		return erro
+	}
+
+	for posicao, opcao := range enquete.Opcoes {
+		if _, erro = transacao.Exec(`
+		insert into enquete_opcoes (publicacao_id, texto, posicao) values (?, ?, ?)`,
+			publicacaoID, opcao.Texto, posicao); erro != nil {
+			return erro
+		}
+	}
+
+	return transacao.Commit()
+}
+
+// BuscarEnquete retorna a enquete de uma publicação sem os votos. Se a publicação
+// não tiver enquete, a enquete retornada não tem opções
+func (repo RepositorioEnquetes) BuscarEnquete(publicacaoID uint64) (modelos.Enquete, error) {
+	var enquete modelos.Enquete
+	var encerraEm sql.NullTime
+
+	erro := repo.db.QueryRow(`
+	select multipla, encerraEm, ocultar_resultados from enquetes where publicacao_id = ?`, publicacaoID).
+		Scan(&enquete.Multipla, &encerraEm, &enquete.OcultarResultados)
+	if erro == sql.ErrNoRows {
+		return modelos.Enquete{}, nil
+	}
+	if erro != nil {
+		return modelos.Enquete{}, erro
+	}
+
+	if encerraEm.Valid {
+		enquete.EncerraEm = &encerraEm.Time
+	}
+
+	linhas, erro := repo.db.Query(`
+	select id, texto from enquete_opcoes where publicacao_id = ? order by posicao`, publicacaoID)
+	if erro != nil {
+		return modelos.Enquete{}, erro
+	}
+
+	defer linhas.Close()
+
+	for linhas.Next() {
+		var opcao modelos.OpcaoEnquete
+
+		if erro = linhas.Scan(&opcao.ID, &opcao.Texto); erro != nil {
+			return modelos.Enquete{}, erro
+		}
+
+		enquete.Opcoes = append(enquete.Opcoes, opcao)
+	}
+
+	enquete.Encerrada = enquete.EstaEncerrada()
+	return enquete, linhas.Err()
+}
+
+// Votar registra o voto do usuário nas opções escolhidas. A chave da tabela de votos garante
+// um único voto por usuário em cada enquete; retorna false se ele já tinha votado
+func (repo RepositorioEnquetes) Votar(publicacaoID, usuarioID uint64, opcoes []uint64) (bool, error) {
+	transacao, erro := repo.db.Begin()
+	if erro != nil {
+		return false, erro
+	}
+
+	defer transacao.Rollback()
+
+	resultado, erro := transacao.Exec(`
+	insert ignore into enquete_votos (publicacao_id, usuario_id)
+	select publicacao_id, ? from enquetes
+	where publicacao_id = ? and (encerraEm is null or encerraEm > now())`, usuarioID, publicacaoID)
+	if erro != nil {
+		return false, erro
+	}
+
+	if linhasAfetadas, erro := resultado.RowsAffected(); erro != nil || linhasAfetadas == 0 {
+		return false, erro
+	}
+
+	for _, opcaoID := range opcoes {
+		if _, erro = transacao.Exec(`
+		insert into enquete_escolhas (publicacao_id, usuario_id, opcao_id)
+		select publicacao_id, ?, id from enquete_opcoes where id = ? and publicacao_id = ?`,
+			usuarioID, opcaoID, publicacaoID); erro != nil {
+			return false, erro
+		}
+	}
+
+	if erro = transacao.Commit(); erro != nil {
+		return false, erro
+	}
+
+	return true, nil
+}
+
+// carregarEnquetes preenche as enquetes das publicações. A contagem de votos só é exibida se a
+// enquete não oculta os resultados, se já foi encerrada, se o leitor é o autor ou se ele já votou
+func carregarEnquetes(db *sql.DB, publicacoes []modelos.Publicacao, leitorID uint64) error {
+	if len(publicacoes) == 0 {
+		return nil
+	}
+
+	indices := make(map[uint64][]int, len(publicacoes))
+	ids := make([]interface{}, 0, len(publicacoes))
+	for i, publicacao := range publicacoes {
+		indices[publicacao.ID] = append(indices[publicacao.ID], i)
+		ids = append(ids, publicacao.ID)
+	}
+
+	enquetes := make(map[uint64]*modelos.Enquete)
+	totais := make(map[uint64]uint64)
+
+	linhas, erro := db.Query(`
+	select e.publicacao_id, e.multipla, e.encerraEm, e.ocultar_resultados,
+	(select count(*) from enquete_votos v where v.publicacao_id = e.publicacao_id)
+	from enquetes e where e.publicacao_id in (`+marcadores(len(ids))+`)`, ids...)
+	if erro != nil {
+		return erro
+	}
+
+	defer linhas.Close()
+
+	for linhas.Next() {
+		var publicacaoID, total uint64
+		var encerraEm sql.NullTime
+		enquete := &modelos.Enquete{}
+
+		if erro = linhas.Scan(&publicacaoID, &enquete.Multipla, &encerraEm, &enquete.OcultarResultados, &total); erro != nil {
+			return erro
+		}
+
+		if encerraEm.Valid {
+			enquete.EncerraEm = &encerraEm.Time
+		}
+
+		enquetes[publicacaoID] = enquete
+		totais[publicacaoID] = total
+	}
+
+	if erro = linhas.Err(); erro != nil {
+		return erro
+	}
+
+	if len(enquetes) == 0 {
+		return nil
+	}
+
+	votos := make(map[uint64]map[uint64]uint64)
+	if erro = carregarOpcoesDasEnquetes(db, enquetes, votos, ids); erro != nil {
+		return erro
+	}
+
+	meusVotos := make(map[uint64][]uint64)
+	if leitorID != 0 {
+		linhasVotos, erro := db.Query(`
+		select publicacao_id, opcao_id from enquete_escolhas
+		where usuario_id = ? and publicacao_id in (`+marcadores(len(ids))+`)`, append([]interface{}{leitorID}, ids...)...)
+		if erro != nil {
+			return erro
+		}
+
+		defer linhasVotos.Close()
+
+		for linhasVotos.Next() {
+			var publicacaoID, opcaoID uint64
+
+			if erro = linhasVotos.Scan(&publicacaoID, &opcaoID); erro != nil {
+				return erro
+			}
+
+			meusVotos[publicacaoID] = append(meusVotos[publicacaoID], opcaoID)
+		}
+
+		if erro = linhasVotos.Err(); erro != nil {
+			return erro
+		}
+	}
+
+	for publicacaoID, modelo := range enquetes {
+		for _, i := range indices[publicacaoID] {
+			enquete := *modelo
+			enquete.Opcoes = append([]modelos.OpcaoEnquete(nil), modelo.Opcoes...)
+			enquete.Encerrada = enquete.EstaEncerrada()
+			enquete.MeusVotos = meusVotos[publicacaoID]
+			enquete.ResultadosVisiveis = !enquete.OcultarResultados || enquete.Encerrada ||
+				len(enquete.MeusVotos) > 0 || (leitorID != 0 && publicacoes[i].AutorID == leitorID)
+
+			if enquete.ResultadosVisiveis {
+				total := totais[publicacaoID]
+				enquete.TotalVotantes = &total
+
+				for j := range enquete.Opcoes {
+					quantidade := votos[publicacaoID][enquete.Opcoes[j].ID]
+					enquete.Opcoes[j].Votos = &quantidade
+				}
+			}
+
+			publicacoes[i].Enquete = &enquete
+		}
+	}
+
+	return nil
+}
+
+// carregarOpcoesDasEnquetes preenche as opções das enquetes e guarda em votos a contagem de cada uma
+func carregarOpcoesDasEnquetes(db *sql.DB, enquetes map[uint64]*modelos.Enquete, votos map[uint64]map[uint64]uint64, ids []interface{}) error {
+	linhas, erro := db.Query(`
+	select o.publicacao_id, o.id, o.texto,
+	(select count(*) from enquete_escolhas c where c.opcao_id = o.id)
+	from enquete_opcoes o where o.publicacao_id in (`+marcadores(len(ids))+`)
+	order by o.publicacao_id, o.posicao`, ids...)
+	if erro != nil {
+		return erro
+	}
+
+	defer linhas.Close()
+
+	for linhas.Next() {
+		var publicacaoID, quantidade uint64
+		var opcao modelos.OpcaoEnquete
+
+		if erro = linhas.Scan(&publicacaoID, &opcao.ID, &opcao.Texto, &quantidade); erro != nil {
+			return erro
+		}
+
+		enquete, existe := enquetes[publicacaoID]
+		if !existe {
+			continue
+		}
+
+		enquete.Opcoes = append(enquete.Opcoes, opcao)
+		if votos[publicacaoID] == nil {
+			votos[publicacaoID] = make(map[uint64]uint64)
+		}
+		votos[publicacaoID][opcao.ID] = quantidade
+	}
+
+	return linhas.Err()
+}
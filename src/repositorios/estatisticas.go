@@ -0,0 +1,222 @@
+package repositorios
+
+import (
+	"api/src/modelos"
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// tamanhoLoteVisualizacoes é quantas contagens são gravadas em cada insert
+const tamanhoLoteVisualizacoes = 500
+
+// limiteTopPublicacoes é quantas publicações aparecem no ranking das estatísticas
+const limiteTopPublicacoes = 5
+
+// RepositorioEstatisticas representa um repositorio de estatísticas de alcance
+type RepositorioEstatisticas struct {
+	db *sql.DB
+}
+
+// NovoRepositorioDeEstatisticas cria um repositorio de estatísticas
+func NovoRepositorioDeEstatisticas(db *sql.DB) *RepositorioEstatisticas {
+	return &RepositorioEstatisticas{db}
+}
+
+// GravarVisualizacoes soma as contagens às estatísticas diárias das publicações, em lotes.
+// Os lotes são gravados em uma transação, então ou todas as contagens entram ou nenhuma.
+// Contagens de publicações que já foram apagadas são descartadas
+func (repo RepositorioEstatisticas) GravarVisualizacoes(contagens []modelos.ContagemVisualizacoes) error {
+	transacao, erro := repo.db.Begin()
+	if erro != nil {
+		return erro
+	}
+
+	defer transacao.Rollback()
+
+	for inicio := 0; inicio < len(contagens); inicio += tamanhoLoteVisualizacoes {
+		fim := inicio + tamanhoLoteVisualizacoes
+		if fim > len(contagens) {
+			fim = len(contagens)
+		}
+
+		lote, erro := publicacoesExistentes(transacao, contagens[inicio:fim])
+		if erro != nil {
+			return erro
+		}
+
+		if len(lote) == 0 {
+			continue
+		}
+
+		valores := make([]interface{}, 0, len(lote)*4)
+		linhas := make([]string, 0, len(lote))
+
+		for _, contagem := range lote {
+			valores = append(valores, contagem.PublicacaoID, contagem.Dia, contagem.Impressoes, contagem.Visualizacoes)
+			linhas = append(linhas, "("+marcadores(4)+")")
+		}
+
+		if _, erro = transacao.Exec(`
+		insert into estatisticas_publicacoes (publicacao_id, dia, impressoes, visualizacoes)
+		values `+strings.Join(linhas, ", ")+`
+		on duplicate key update impressoes = impressoes + values(impressoes),
+		visualizacoes = visualizacoes + values(visualizacoes)`, valores...); erro != nil {
+			return erro
+		}
+	}
+
+	return transacao.Commit()
+}
+
+// publicacoesExistentes retorna as contagens cujas publicações ainda existem no banco. As
+// publicações ficam travadas até o fim da transação, para que o expurgo não as apague antes
+// de as contagens serem gravadas
+func publicacoesExistentes(transacao *sql.Tx, contagens []modelos.ContagemVisualizacoes) ([]modelos.ContagemVisualizacoes, error) {
+	ids := make([]interface{}, 0, len(contagens))
+	for _, contagem := range contagens {
+		ids = append(ids, contagem.PublicacaoID)
+	}
+
+	linhas, erro := transacao.Query(`
+	select id from publicacoes where id in (`+marcadores(len(ids))+`) lock in share mode`, ids...)
+	if erro != nil {
+		return nil, erro
+	}
+
+	defer linhas.Close()
+
+	existentes := make(map[uint64]bool, len(ids))
+	for linhas.Next() {
+		var id uint64
+
+		if erro = linhas.Scan(&id); erro != nil {
+			return nil, erro
+		}
+
+		existentes[id] = true
+	}
+
+	if erro = linhas.Err(); erro != nil {
+		return nil, erro
+	}
+
+	var lote []modelos.ContagemVisualizacoes
+	for _, contagem := range contagens {
+		if existentes[contagem.PublicacaoID] {
+			lote = append(lote, contagem)
+		}
+	}
+
+	return lote, nil
+}
+
+// Buscar retorna as estatísticas do usuário entre as datas de e ate (inclusive, no formato modelos.FormatoDia)
+func (repo RepositorioEstatisticas) Buscar(usuarioID uint64, de, ate string) (modelos.Estatisticas, error) {
+	estatisticas := modelos.Estatisticas{
+		De:             de,
+		Ate:            ate,
+		Dias:           []modelos.EstatisticaDiaria{},
+		TopPublicacoes: []modelos.EstatisticaPublicacao{},
+	}
+
+	linhas, erro := repo.db.Query(`
+	select x.dia, sum(x.impressoes), sum(x.visualizacoes), sum(x.curtidas), sum(x.reacoes), sum(x.seguidores) from (
+		select e.dia, e.impressoes, e.visualizacoes, e.curtidas, 0 as reacoes, 0 as seguidores
+		from estatisticas_publicacoes e
+		inner join publicacoes p on p.id = e.publicacao_id
+		where p.autor_id = ? and e.dia between ? and ?
+		union all
+		select date(r.criadaEm), 0, 0, 0, 1, 0 from reacoes r
+		inner join publicacoes p on p.id = r.publicacao_id
+		where p.autor_id = ? and r.usuario_id <> p.autor_id and date(r.criadaEm) between ? and ?
+		union all
+		select date(s.criadoEm), 0, 0, 0, 0, 1 from seguidores s
+		where s.usuario_id = ? and date(s.criadoEm) between ? and ?
+	) x
+	group by x.dia
+	order by x.dia`, usuarioID, de, ate, usuarioID, de, ate, usuarioID, de, ate)
+	if erro != nil {
+		return modelos.Estatisticas{}, erro
+	}
+
+	defer linhas.Close()
+
+	for linhas.Next() {
+		var dia modelos.EstatisticaDiaria
+		var data time.Time
+
+		if erro = linhas.Scan(&data, &dia.Impressoes, &dia.Visualizacoes, &dia.Curtidas, &dia.Reacoes,
+			&dia.NovosSeguidores); erro != nil {
+			return modelos.Estatisticas{}, erro
+		}
+
+		dia.Dia = data.Format(modelos.FormatoDia)
+		estatisticas.Dias = append(estatisticas.Dias, dia)
+
+		estatisticas.Impressoes += dia.Impressoes
+		estatisticas.Visualizacoes += dia.Visualizacoes
+		estatisticas.Curtidas += dia.Curtidas
+		estatisticas.Reacoes += dia.Reacoes
+		estatisticas.NovosSeguidores += dia.NovosSeguidores
+	}
+
+	if erro = linhas.Err(); erro != nil {
+		return modelos.Estatisticas{}, erro
+	}
+
+	if erro = repo.db.QueryRow(`
+	select count(*) from seguidores s inner join usuarios o on o.id = s.seguidor_id
+	where s.usuario_id = ? and o.deletadoEm is null`, usuarioID).Scan(&estatisticas.Seguidores); erro != nil {
+		return modelos.Estatisticas{}, erro
+	}
+
+	if estatisticas.TopPublicacoes, erro = repo.buscarTopPublicacoes(usuarioID, de, ate); erro != nil {
+		return modelos.Estatisticas{}, erro
+	}
+
+	return estatisticas, nil
+}
+
+// buscarTopPublicacoes retorna as publicações do usuário mais vistas no período
+func (repo RepositorioEstatisticas) buscarTopPublicacoes(usuarioID uint64, de, ate string) ([]modelos.EstatisticaPublicacao, error) {
+	linhas, erro := repo.db.Query(`
+	select p.id, p.titulo, p.criadaEm, sum(e.impressoes), sum(e.visualizacoes), sum(e.curtidas),
+	(select count(*) from reacoes r
+		where r.publicacao_id = p.id and r.usuario_id <> p.autor_id and date(r.criadaEm) between ? and ?) as reacoes
+	from publicacoes p
+	inner join estatisticas_publicacoes e on e.publicacao_id = p.id
+	where p.autor_id = ? and p.deletadaEm is null and e.dia between ? and ?
+	group by p.id, p.titulo, p.criadaEm
+	order by sum(e.visualizacoes) desc, sum(e.impressoes) desc, p.id desc
+	limit ?`, de, ate, usuarioID, de, ate, limiteTopPublicacoes)
+	if erro != nil {
+		return nil, erro
+	}
+
+	defer linhas.Close()
+
+	publicacoes := []modelos.EstatisticaPublicacao{}
+
+	for linhas.Next() {
+		var publicacao modelos.EstatisticaPublicacao
+
+		if erro = linhas.Scan(&publicacao.PublicacaoID, &publicacao.Titulo, &publicacao.CriadaEm, &publicacao.Impressoes,
+			&publicacao.Visualizacoes, &publicacao.Curtidas, &publicacao.Reacoes); erro != nil {
+			return nil, erro
+		}
+
+		publicacoes = append(publicacoes, publicacao)
+	}
+
+	return publicacoes, linhas.Err()
+}
+
+// registrarCurtida soma a variação ao saldo de curtidas do dia da publicação
+func registrarCurtida(transacao *sql.Tx, publicacaoID uint64, variacao int) error {
+	_, erro := transacao.Exec(`
+	insert into estatisticas_publicacoes (publicacao_id, dia, curtidas) values (?, ?, ?)
+	on duplicate key update curtidas = curtidas + values(curtidas)`,
+		publicacaoID, time.Now().Format(modelos.FormatoDia), variacao)
+	return erro
+}
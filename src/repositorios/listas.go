@@ -0,0 +1,250 @@
+package repositorios
+
+import (
+	"api/src/modelos"
+	"database/sql"
+)
+
+// colunasLista são as colunas lidas por escanearListas. O único parâmetro é o leitor
+const colunasLista = `l.id, l.nome, l.descricao, l.privada, l.dono_id, u.nick, l.criadaEm,
+	(select count(*) from lista_membros lm inner join usuarios m on m.id = lm.usuario_id
+		where lm.lista_id = l.id and m.deletadoEm is null) as membros,
+	(select count(*) from listas_seguidas ls where ls.lista_id = l.id) as seguidores,
+	exists (select 1 from listas_seguidas ls where ls.lista_id = l.id and ls.usuario_id = ?) as seguida`
+
+// RepositorioListas representa um repositorio de listas de usuários
+type RepositorioListas struct {
+	db *sql.DB
+}
+
+// NovoRepositorioDeListas cria um repositorio de listas
+func NovoRepositorioDeListas(db *sql.DB) *RepositorioListas {
+	return &RepositorioListas{db}
+}
+
+// Criar salva uma lista no banco de dados
+func (repo RepositorioListas) Criar(lista modelos.Lista) (uint64, error) {
+	statement, erro := repo.db.Prepare("insert into listas (nome, descricao, privada, dono_id) values (?, ?, ?, ?)")
+	if erro != nil {
+		return 0, erro
+	}
+
+	defer statement.Close()
+
+	resultado, erro := statement.Exec(lista.Nome, lista.Descricao, lista.Privada, lista.DonoID)
+	if erro != nil {
+		return 0, erro
+	}
+
+	ultimoIdInserido, erro := resultado.LastInsertId()
+	if erro != nil {
+		return 0, erro
+	}
+
+	return uint64(ultimoIdInserido), nil
+}
+
+// BuscarPorID retorna uma lista. Listas privadas só são retornadas para o dono
+func (repo RepositorioListas) BuscarPorID(listaID, leitorID uint64) (modelos.Lista, error) {
+	linhas, erro := repo.db.Query(`
+	select `+colunasLista+` from listas l
+	inner join usuarios u on u.id = l.dono_id
+	where l.id = ? and (l.privada = false or l.dono_id = ?) and u.deletadoEm is null`,
+		leitorID, listaID, leitorID)
+	if erro != nil {
+		return modelos.Lista{}, erro
+	}
+
+	defer linhas.Close()
+
+	listas, erro := escanearListas(linhas)
+	if erro != nil || len(listas) == 0 {
+		return modelos.Lista{}, erro
+	}
+
+	return listas[0], nil
+}
+
+// BuscarListasDoUsuario retorna as listas criadas por um usuário. As privadas só aparecem para o dono
+func (repo RepositorioListas) BuscarListasDoUsuario(donoID, leitorID uint64) ([]modelos.Lista, error) {
+	linhas, erro := repo.db.Query(`
+	select `+colunasLista+` from listas l
+	inner join usuarios u on u.id = l.dono_id
+	where l.dono_id = ? and (l.privada = false or l.dono_id = ?) and u.deletadoEm is null
+	order by l.nome`, leitorID, donoID, leitorID)
+	if erro != nil {
+		return nil, erro
+	}
+
+	defer linhas.Close()
+
+	return escanearListas(linhas)
+}
+
+// BuscarListasSeguidas retorna as listas de outros usuários que o usuário segue e que continuam públicas
+func (repo RepositorioListas) BuscarListasSeguidas(usuarioID uint64) ([]modelos.Lista, error) {
+	linhas, erro := repo.db.Query(`
+	select `+colunasLista+` from listas l
+	inner join usuarios u on u.id = l.dono_id
+	inner join listas_seguidas s on s.lista_id = l.id and s.usuario_id = ?
+	where l.privada = false and u.deletadoEm is null
+	order by s.criadoEm desc`, usuarioID, usuarioID)
+	if erro != nil {
+		return nil, erro
+	}
+
+	defer linhas.Close()
+
+	return escanearListas(linhas)
+}
+
+// Atualizar altera o nome, a descrição e a privacidade de uma lista. Ao ficar privada,
+// ela deixa de ser seguida por outros usuários
+func (repo RepositorioListas) Atualizar(listaID uint64, lista modelos.Lista) error {
+	transacao, erro := repo.db.Begin()
+	if erro != nil {
+		return erro
+	}
+
+	defer transacao.Rollback()
+
+	if _, erro = transacao.Exec(
+		"update listas set nome = ?, descricao = ?, privada = ? where id = ?",
+		lista.Nome, lista.Descricao, lista.Privada, listaID); erro != nil {
+		return erro
+	}
+
+	if lista.Privada {
+		if _, erro = transacao.Exec("delete from listas_seguidas where lista_id = ?", listaID); erro != nil {
+			return erro
+		}
+	}
+
+	return transacao.Commit()
+}
+
+// Deletar apaga uma lista
+func (repo RepositorioListas) Deletar(listaID uint64) error {
+	statement, erro := repo.db.Prepare("delete from listas where id = ?")
+	if erro != nil {
+		return erro
+	}
+
+	defer statement.Close()
+
+	if _, erro = statement.Exec(listaID); erro != nil {
+		return erro
+	}
+
+	return nil
+}
+
+// AdicionarMembro inclui um usuário na lista
+func (repo RepositorioListas) AdicionarMembro(listaID, usuarioID uint64) error {
+	statement, erro := repo.db.Prepare("insert ignore into lista_membros (lista_id, usuario_id) values (?, ?)")
+	if erro != nil {
+		return erro
+	}
+
+	defer statement.Close()
+
+	if _, erro = statement.Exec(listaID, usuarioID); erro != nil {
+		return erro
+	}
+
+	return nil
+}
+
+// RemoverMembro tira um usuário da lista
+func (repo RepositorioListas) RemoverMembro(listaID, usuarioID uint64) error {
+	statement, erro := repo.db.Prepare("delete from lista_membros where lista_id = ? and usuario_id = ?")
+	if erro != nil {
+		return erro
+	}
+
+	defer statement.Close()
+
+	if _, erro = statement.Exec(listaID, usuarioID); erro != nil {
+		return erro
+	}
+
+	return nil
+}
+
+// BuscarMembros retorna os membros de uma lista, dos adicionados mais recentemente para os mais antigos
+func (repo RepositorioListas) BuscarMembros(listaID, limite, deslocamento uint64) ([]modelos.UsuarioResumido, error) {
+	linhas, erro := repo.db.Query(`
+	select u.id, u.nome, u.nick from lista_membros lm
+	inner join usuarios u on u.id = lm.usuario_id
+	where lm.lista_id = ? and u.deletadoEm is null
+	order by lm.criadoEm desc, u.id
+	limit ? offset ?`, listaID, limite, deslocamento)
+	if erro != nil {
+		return nil, erro
+	}
+
+	defer linhas.Close()
+
+	var membros []modelos.UsuarioResumido
+
+	for linhas.Next() {
+		var membro modelos.UsuarioResumido
+
+		if erro = linhas.Scan(&membro.ID, &membro.Nome, &membro.Nick); erro != nil {
+			return nil, erro
+		}
+
+		membros = append(membros, membro)
+	}
+
+	return membros, linhas.Err()
+}
+
+// Seguir faz o usuário seguir uma lista
+func (repo RepositorioListas) Seguir(listaID, usuarioID uint64) error {
+	statement, erro := repo.db.Prepare("insert ignore into listas_seguidas (lista_id, usuario_id) values (?, ?)")
+	if erro != nil {
+		return erro
+	}
+
+	defer statement.Close()
+
+	if _, erro = statement.Exec(listaID, usuarioID); erro != nil {
+		return erro
+	}
+
+	return nil
+}
+
+// DeixarDeSeguir faz o usuário parar de seguir uma lista
+func (repo RepositorioListas) DeixarDeSeguir(listaID, usuarioID uint64) error {
+	statement, erro := repo.db.Prepare("delete from listas_seguidas where lista_id = ? and usuario_id = ?")
+	if erro != nil {
+		return erro
+	}
+
+	defer statement.Close()
+
+	if _, erro = statement.Exec(listaID, usuarioID); erro != nil {
+		return erro
+	}
+
+	return nil
+}
+
+func escanearListas(linhas *sql.Rows) ([]modelos.Lista, error) {
+	var listas []modelos.Lista
+
+	for linhas.Next() {
+		var lista modelos.Lista
+
+		if erro := linhas.Scan(&lista.ID, &lista.Nome, &lista.Descricao, &lista.Privada, &lista.DonoID,
+			&lista.DonoNick, &lista.CriadaEm, &lista.Membros, &lista.Seguidores, &lista.SeguidaPorMim); erro != nil {
+			return nil, erro
+		}
+
+		listas = append(listas, lista)
+	}
+
+	return listas, linhas.Err()
+}
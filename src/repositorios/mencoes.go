@@ -0,0 +1,113 @@
+package repositorios
+
+import (
+	"api/src/modelos"
+	"database/sql"
+)
+
+// RepositorioMencoes representa um repositorio de menções
+type RepositorioMencoes struct {
+	db *sql.DB
+}
+
+// NovoRepositorioDeMencoes cria um repositorio de menções
+func NovoRepositorioDeMencoes(db *sql.DB) *RepositorioMencoes {
+	return &RepositorioMencoes{db}
+}
+
+// Resolver associa as menções aos usuários pelo nick, descartando nicks inexistentes
+// e usuários que bloquearam o autor da publicação
+func (repo RepositorioMencoes) Resolver(autorID uint64, mencoes []modelos.Mencao) ([]modelos.Mencao, error) {
+	var resolvidas []modelos.Mencao
+
+	for _, mencao := range mencoes {
+		linha := repo.db.QueryRow(`
+		select u.id, u.nick from usuarios u
+		where u.nick = ? and u.deletadoEm is null
+		and not exists (select 1 from bloqueios b where b.usuario_id = u.id and b.bloqueado_id = ?)
+		`, mencao.Nick, autorID)
+
+		if erro := linha.Scan(&mencao.UsuarioID, &mencao.Nick); erro != nil {
+			if erro == sql.ErrNoRows {
+				continue
+			}
+			return nil, erro
+		}
+
+		resolvidas = append(resolvidas, mencao)
+	}
+
+	return resolvidas, nil
+}
+
+// SalvarMencoesDaPublicacao substitui as menções de uma publicação
+func (repo RepositorioMencoes) SalvarMencoesDaPublicacao(publicacaoID uint64, mencoes []modelos.Mencao) error {
+	if _, erro := repo.db.Exec("delete from mencoes where publicacao_id = ?", publicacaoID); erro != nil {
+		return erro
+	}
+
+	statement, erro := repo.db.Prepare("insert into mencoes (publicacao_id, usuario_id, campo, inicio, tamanho) values (?, ?, ?, ?, ?)")
+	if erro != nil {
+		return erro
+	}
+
+	defer statement.Close()
+
+	for _, mencao := range mencoes {
+		if _, erro = statement.Exec(publicacaoID, mencao.UsuarioID, mencao.Campo, mencao.Inicio, mencao.Tamanho); erro != nil {
+			return erro
+		}
+	}
+
+	return nil
+}
+
+// BuscarMencionados retorna os IDs dos usuários mencionados em uma publicação
+func (repo RepositorioMencoes) BuscarMencionados(publicacaoID uint64) (map[uint64]bool, error) {
+	linhas, erro := repo.db.Query("select distinct usuario_id from mencoes where publicacao_id = ?", publicacaoID)
+	if erro != nil {
+		return nil, erro
+	}
+
+	defer linhas.Close()
+
+	mencionados := make(map[uint64]bool)
+
+	for linhas.Next() {
+		var usuarioID uint64
+
+		if erro = linhas.Scan(&usuarioID); erro != nil {
+			return nil, erro
+		}
+
+		mencionados[usuarioID] = true
+	}
+
+	return mencionados, linhas.Err()
+}
+
+// BuscarPublicacoesComMencao retorna as publicações que mencionam um usuário e que o leitor
+// pode ver, ignorando as de autores que o usuário mencionado bloqueou
+func (repo RepositorioMencoes) BuscarPublicacoesComMencao(usuarioID, leitorID uint64) ([]modelos.Publicacao, error) {
+	linhas, erro := repo.db.Query(`
+	select `+colunasPublicacao+` from publicacoes p
+	inner join usuarios u on u.id = p.autor_id
+	where exists (select 1 from mencoes m where m.publicacao_id = p.id and m.usuario_id = ?)
+	and not exists (select 1 from bloqueios b where b.usuario_id = ? and b.bloqueado_id = p.autor_id)
+	and `+filtroLeitura+`
+	order by p.criadaEm desc, p.id desc
+	`, usuarioID, usuarioID, leitorID)
+
+	if erro != nil {
+		return nil, erro
+	}
+
+	defer linhas.Close()
+
+	publicacoes, erro := escanearPublicacoes(linhas)
+	if erro != nil {
+		return nil, erro
+	}
+
+	return publicacoes, completarPublicacoes(repo.db, publicacoes, leitorID)
+}
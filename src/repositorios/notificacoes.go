@@ -0,0 +1,186 @@
+package repositorios
+
+import (
+	"api/src/modelos"
+	"database/sql"
+	"time"
+)
+
+// RepositorioNotificacoes representa um repositorio de notificações
+type RepositorioNotificacoes struct {
+	db *sql.DB
+}
+
+// NovoRepositorioDeNotificacoes cria um repositorio de notificações
+func NovoRepositorioDeNotificacoes(db *sql.DB) *RepositorioNotificacoes {
+	return &RepositorioNotificacoes{db}
+}
+
+// Criar registra uma notificação, a menos que o destinatário seja o próprio ator, tenha
+// desativado o tipo de notificação ou tenha silenciado o ator. Retorna se a notificação foi criada
+func (repo RepositorioNotificacoes) Criar(usuarioID, atorID uint64, tipo string, publicacaoID uint64) (bool, error) {
+	statement, erro := repo.db.Prepare(`
+	insert into notificacoes (usuario_id, ator_id, tipo, publicacao_id)
+	select ?, ?, ?, nullif(?, 0) from dual
+	where ? <> ?
+	and not exists (
+		select 1 from preferencias_notificacoes
+		where usuario_id = ? and tipo = ? and ativa = false
+	)
+	and not exists (
+		select 1 from silenciamentos
+		where usuario_id = ? and silenciado_id = ?
+	)`)
+	if erro != nil {
+		return false, erro
+	}
+
+	defer statement.Close()
+
+	resultado, erro := statement.Exec(usuarioID, atorID, tipo, publicacaoID,
+		usuarioID, atorID, usuarioID, tipo, usuarioID, atorID)
+	if erro != nil {
+		return false, erro
+	}
+
+	linhasAfetadas, erro := resultado.RowsAffected()
+	if erro != nil {
+		return false, erro
+	}
+
+	return linhasAfetadas > 0, nil
+}
+
+// periodoNotificacao numera os períodos de agrupamento das notificações: as de um mesmo período
+// têm o mesmo número. O único parâmetro é a duração do período em segundos
+const periodoNotificacao = `floor(unix_timestamp(criadaEm) / ?)`
+
+// Buscar retorna as notificações de um usuário agrupadas por tipo, publicação e período de
+// duração janela, para que notificações de dias distantes não se juntem no mesmo grupo
+func (repo RepositorioNotificacoes) Buscar(usuarioID uint64, somenteNaoLidas bool, janela time.Duration, limite, deslocamento uint64) ([]modelos.Notificacao, error) {
+	linhas, erro := repo.db.Query(`
+	select g.ultimo_id, g.tipo, coalesce(g.publicacao_id, 0), g.total, g.lida, g.criadaEm, u.id, u.nick
+	from (
+		select tipo, publicacao_id, lida, count(distinct ator_id) as total,
+		max(id) as ultimo_id, max(criadaEm) as criadaEm
+		from notificacoes
+		where usuario_id = ? and (? = false or lida = false)
+		and not exists (select 1 from usuarios a where a.id = ator_id and a.deletadoEm is not null)
+		and not exists (select 1 from publicacoes p where p.id = publicacao_id and p.deletadaEm is not null)
+		group by tipo, publicacao_id, lida, `+periodoNotificacao+`
+	) g
+	inner join notificacoes n on n.id = g.ultimo_id
+	inner join usuarios u on u.id = n.ator_id
+	order by g.ultimo_id desc
+	limit ? offset ?
+	`, usuarioID, somenteNaoLidas, int64(janela.Seconds()), limite, deslocamento)
+
+	if erro != nil {
+		return nil, erro
+	}
+
+	defer linhas.Close()
+
+	var notificacoes []modelos.Notificacao
+
+	for linhas.Next() {
+		var notificacao modelos.Notificacao
+
+		if erro = linhas.Scan(&notificacao.ID, &notificacao.Tipo, &notificacao.PublicacaoID, &notificacao.Total,
+			&notificacao.Lida, &notificacao.CriadaEm, &notificacao.AtorID, &notificacao.AtorNick); erro != nil {
+			return nil, erro
+		}
+
+		notificacao.FormatarMensagem()
+		notificacoes = append(notificacoes, notificacao)
+	}
+
+	return notificacoes, nil
+}
+
+// MarcarComoLida marca como lidas a notificação e as demais agrupadas com ela no período de duração janela
+func (repo RepositorioNotificacoes) MarcarComoLida(usuarioID, notificacaoID uint64, janela time.Duration) error {
+	statement, erro := repo.db.Prepare(`
+	update notificacoes n
+	inner join notificacoes alvo on alvo.id = ? and alvo.usuario_id = n.usuario_id
+	set n.lida = true
+	where n.usuario_id = ? and n.tipo = alvo.tipo and n.id <= alvo.id
+	and coalesce(n.publicacao_id, 0) = coalesce(alvo.publicacao_id, 0)
+	and floor(unix_timestamp(n.criadaEm) / ?) = floor(unix_timestamp(alvo.criadaEm) / ?)`)
+	if erro != nil {
+		return erro
+	}
+
+	defer statement.Close()
+
+	segundos := int64(janela.Seconds())
+	if _, erro = statement.Exec(notificacaoID, usuarioID, segundos, segundos); erro != nil {
+		return erro
+	}
+
+	return nil
+}
+
+// MarcarTodasComoLidas marca todas as notificações de um usuário como lidas
+func (repo RepositorioNotificacoes) MarcarTodasComoLidas(usuarioID uint64) error {
+	statement, erro := repo.db.Prepare("update notificacoes set lida = true where usuario_id = ? and lida = false")
+	if erro != nil {
+		return erro
+	}
+
+	defer statement.Close()
+
+	if _, erro = statement.Exec(usuarioID); erro != nil {
+		return erro
+	}
+
+	return nil
+}
+
+// BuscarPreferencias retorna, para cada tipo, se o usuário quer receber a notificação
+func (repo RepositorioNotificacoes) BuscarPreferencias(usuarioID uint64) (map[string]bool, error) {
+	preferencias := make(map[string]bool, len(modelos.TiposNotificacao))
+	for _, tipo := range modelos.TiposNotificacao {
+		preferencias[tipo] = true
+	}
+
+	linhas, erro := repo.db.Query("select tipo, ativa from preferencias_notificacoes where usuario_id = ?", usuarioID)
+	if erro != nil {
+		return nil, erro
+	}
+
+	defer linhas.Close()
+
+	for linhas.Next() {
+		var tipo string
+		var ativa bool
+
+		if erro = linhas.Scan(&tipo, &ativa); erro != nil {
+			return nil, erro
+		}
+
+		preferencias[tipo] = ativa
+	}
+
+	return preferencias, nil
+}
+
+// AtualizarPreferencias salva as preferências de notificação de um usuário
+func (repo RepositorioNotificacoes) AtualizarPreferencias(usuarioID uint64, preferencias map[string]bool) error {
+	statement, erro := repo.db.Prepare(`
+	insert into preferencias_notificacoes (usuario_id, tipo, ativa) values (?, ?, ?)
+	on duplicate key update ativa = values(ativa)`)
+	if erro != nil {
+		return erro
+	}
+
+	defer statement.Close()
+
+	for tipo, ativa := range preferencias {
+		if _, erro = statement.Exec(usuarioID, tipo, ativa); erro != nil {
+			return erro
+		}
+	}
+
+	return nil
+}
@@ -1,10 +1,43 @@
 package repositorios
 
 import (
+	"api/src/busca"
 	"api/src/modelos"
 	"database/sql"
+	"strings"
+	"time"
 )
 
+// colunasPublicacao são as colunas lidas por escanearPublicacoes, com as tags concatenadas em uma só
+const colunasPublicacao = `p.id, p.titulo, p.conteudo, p.autor_id, p.curtidas,
+	(select count(*) from repostagens r where r.publicacao_id = p.id) as repostagens,
+	(select count(*) from publicacoes q where q.citacao_id = p.id and q.status = 'publicada' and q.deletadaEm is null) as citacoes,
+	(select count(*) from comentarios c where c.publicacao_id = p.id) as comentarios,
+	coalesce(p.citacao_id, 0), p.fixadaEm is not null as fixada, p.criadaEm, p.editadaEm,
+	p.status, p.publicarEm, p.visibilidade, coalesce(p.comunidade_id, 0), u.nick,
+	(select group_concat(t.nome) from publicacao_tags pt
+	inner join tags t on t.id = pt.tag_id where pt.publicacao_id = p.id) as tags`
+
+// loteCandidatosPublicacoes é quantas publicações são pedidas ao buscador de cada vez para serem
+// filtradas na busca
+const loteCandidatosPublicacoes = 200
+
+// filtroLeitura restringe as publicações às que o leitor (único parâmetro) pode ver: rascunhos,
+// agendadas, excluídas, ocultadas pela moderação ou de autores sem conta ativa não aparecem, as
+// de comunidades privadas só aparecem para os membros e a visibilidade escolhida pelo autor é respeitada
+const filtroLeitura = `(p.status = 'publicada' and p.oculta = false and p.deletadaEm is null
+	and exists (select 1 from usuarios autor where autor.id = p.autor_id and autor.deletadoEm is null
+		and (autor.estado = 'ativo' or (autor.estado = 'suspenso' and autor.suspenso_ate <= now())))
+	and exists (select 1 from (select ? as id) leitor where
+		(p.comunidade_id is null
+		or exists (select 1 from comunidades c where c.id = p.comunidade_id and c.privada = false)
+		or exists (select 1 from comunidade_membros cm where cm.comunidade_id = p.comunidade_id and cm.usuario_id = leitor.id))
+		and (p.visibilidade = 'publico' or p.autor_id = leitor.id
+		or (p.visibilidade = 'seguidores'
+			and exists (select 1 from seguidores s where s.usuario_id = p.autor_id and s.seguidor_id = leitor.id))
+		or (p.visibilidade = 'mencionados'
+			and exists (select 1 from mencoes m where m.publicacao_id = p.id and m.usuario_id = leitor.id)))))`
+
 // Repositorio representa um repositorio de publicacoes
 type RepositorioPublicacoes struct {
 	db *sql.DB
@@ -17,14 +50,17 @@ func NovoRepositorioDePublicacoes(db *sql.DB) *RepositorioPublicacoes {
 
 // Criar salva uma publicação no banco de dados
 func (repo RepositorioPublicacoes) Criar(publicacao modelos.Publicacao) (uint64, error) {
-	statement, erro := repo.db.Prepare("insert into publicacoes(titulo, conteudo, autor_id) values(?, ?, ?)")
+	statement, erro := repo.db.Prepare(`
+	insert into publicacoes(titulo, conteudo, autor_id, comunidade_id, status, publicarEm, visibilidade, citacao_id)
+	values(?, ?, ?, nullif(?, 0), ?, ?, ?, nullif(?, 0))`)
 	if erro != nil {
 		return 0, erro
 	}
 
 	defer statement.Close()
 
-	resultado, erro := statement.Exec(publicacao.Titulo, publicacao.Conteudo, publicacao.AutorID)
+	resultado, erro := statement.Exec(publicacao.Titulo, publicacao.Conteudo, publicacao.AutorID, publicacao.ComunidadeID,
+		publicacao.Status, publicacao.PublicarEm, publicacao.Visibilidade, publicacao.CitacaoID)
 	if erro != nil {
 		return 0, erro
 	}
@@ -34,12 +70,15 @@ func (repo RepositorioPublicacoes) Criar(publicacao modelos.Publicacao) (uint64,
 		return 0, erro
 	}
 
-	return uint64(ultimoIdInserido), nil
+	publicacao.ID = uint64(ultimoIdInserido)
+	busca.Padrao.IndexarPublicacao(publicacao)
+
+	return publicacao.ID, nil
 }
 
-// BuscarPublicacao retorna uma publicação
+// BuscarPublicacao retorna uma publicação que não foi excluída
 func (repo RepositorioPublicacoes) BuscarPublicacao(usuarioID uint64) (modelos.Publicacao, error) {
-	linhas, erro := repo.db.Query("select p.*, u.nick from publicacoes p inner join usuarios u on u.id = p.autor_id where p.id = ?", usuarioID)
+	linhas, erro := repo.db.Query("select "+colunasPublicacao+" from publicacoes p inner join usuarios u on u.id = p.autor_id where p.id = ? and p.deletadaEm is null and u.deletadoEm is null", usuarioID)
 
 	if erro != nil {
 		return modelos.Publicacao{}, erro
@@ -47,26 +86,68 @@ func (repo RepositorioPublicacoes) BuscarPublicacao(usuarioID uint64) (modelos.P
 
 	defer linhas.Close()
 
-	var publicacao modelos.Publicacao
+	publicacoes, erro := escanearPublicacoes(linhas)
+	if erro != nil || len(publicacoes) == 0 {
+		return modelos.Publicacao{}, erro
+	}
 
-	if linhas.Next() {
-		if erro = linhas.Scan(&publicacao.ID, &publicacao.Titulo, &publicacao.Conteudo, &publicacao.AutorID,
-			&publicacao.Curtidas, &publicacao.CriadaEm, &publicacao.AutorNick); erro != nil {
-			return modelos.Publicacao{}, erro
-		}
+	if erro = completarPublicacoes(repo.db, publicacoes, 0); erro != nil {
+		return modelos.Publicacao{}, erro
 	}
 
-	return publicacao, nil
+	return publicacoes[0], nil
 }
 
-// BuscarPublicações retorna todas as publicações dos seguidores de um usuário
+// BuscarPublicações retorna as publicações do usuário, de quem ele segue e das tags que ele segue,
+// além das repostadas por ele e por quem ele segue. Uma publicação aparece uma única vez, na posição
+// da ocorrência mais recente
 func (repo RepositorioPublicacoes) BuscarPublicacoes(usuarioID uint64) ([]modelos.Publicacao, error) {
+	return repo.buscarFeed(usuarioID, `
+		p.autor_id = ?
+		or exists (select 1 from seguidores s where s.usuario_id = p.autor_id and s.seguidor_id = ?)
+		or exists (
+			select 1 from publicacao_tags pt
+			inner join tags_seguidas ts on ts.tag_id = pt.tag_id
+			where pt.publicacao_id = p.id and ts.usuario_id = ?
+		)`, []interface{}{usuarioID, usuarioID, usuarioID},
+		`r.usuario_id = ? or exists (select 1 from seguidores s where s.usuario_id = r.usuario_id and s.seguidor_id = ?)`,
+		[]interface{}{usuarioID, usuarioID})
+}
+
+// buscarFeed monta um feed para o leitor com as publicações (p) que atendem a origens e as
+// repostagens (r) feitas por quem atende a repostadores, cada condição com seus parâmetros.
+// Só entram publicações que o leitor pode ver, de autores sem bloqueio com ele e repostadas
+// por contas ativas, deixando de fora publicações e repostagens de quem o leitor silenciou.
+// Uma publicação aparece uma única vez, na posição da ocorrência mais recente
+func (repo RepositorioPublicacoes) buscarFeed(leitorID uint64, origens string, parametrosOrigens []interface{},
+	repostadores string, parametrosRepostadores []interface{}) ([]modelos.Publicacao, error) {
+	parametros := append(append([]interface{}{}, parametrosOrigens...), parametrosRepostadores...)
+	parametros = append(parametros, leitorID, leitorID, leitorID, leitorID)
+
 	linhas, erro := repo.db.Query(`
-	select distinct p.*, u.nick from publicacoes p 
-	inner join usuarios u on u.id = p.autor_id 
-	inner join seguidores s on p.autor_id = s.usuario_id
-	where u.id = ? or s.seguidor_id = ?
-	`, usuarioID, usuarioID)
+	select `+colunasPublicacao+`, f.repostador_id, f.repostador_nick, f.momento from (
+		select p.id as publicacao_id, 0 as repostador_id, '' as repostador_nick, p.criadaEm as momento
+		from publicacoes p
+		where (`+origens+`)
+		union all
+		select r.publicacao_id, r.usuario_id, ru.nick, r.criadaEm from repostagens r
+		inner join usuarios ru on ru.id = r.usuario_id
+		where (`+repostadores+`)
+		and ru.deletadoEm is null and (ru.estado = 'ativo' or (ru.estado = 'suspenso' and ru.suspenso_ate <= now()))
+	) f
+	inner join publicacoes p on p.id = f.publicacao_id
+	inner join usuarios u on u.id = p.autor_id
+	where not exists (
+		select 1 from bloqueios b
+		where (b.usuario_id = ? and b.bloqueado_id = p.autor_id) or (b.usuario_id = p.autor_id and b.bloqueado_id = ?)
+	)
+	and not exists (
+		select 1 from silenciamentos sl
+		where sl.usuario_id = ? and sl.silenciado_id in (p.autor_id, f.repostador_id)
+	)
+	and `+filtroLeitura+`
+	order by f.momento desc, p.id desc
+	`, parametros...)
 
 	if erro != nil {
 		return []modelos.Publicacao{}, erro
@@ -75,40 +156,121 @@ func (repo RepositorioPublicacoes) BuscarPublicacoes(usuarioID uint64) ([]modelo
 	defer linhas.Close()
 
 	var publicacoes []modelos.Publicacao
+	incluidas := make(map[uint64]bool)
 
 	for linhas.Next() {
-		var publicacao modelos.Publicacao
+		var repostagem modelos.Repostagem
 
-		if erro = linhas.Scan(&publicacao.ID, &publicacao.Titulo, &publicacao.Conteudo, &publicacao.AutorID,
-			&publicacao.Curtidas, &publicacao.CriadaEm, &publicacao.AutorNick); erro != nil {
+		publicacao, erro := escanearPublicacao(linhas, &repostagem.UsuarioID, &repostagem.UsuarioNick, &repostagem.CriadaEm)
+		if erro != nil {
 			return nil, erro
 		}
 
+		if incluidas[publicacao.ID] {
+			continue
+		}
+		incluidas[publicacao.ID] = true
+
+		if repostagem.UsuarioID != 0 {
+			publicacao.Repostagem = &repostagem
+		}
+
 		publicacoes = append(publicacoes, publicacao)
 	}
 
-	return publicacoes, nil
+	if erro = linhas.Err(); erro != nil {
+		return nil, erro
+	}
+
+	return publicacoes, completarPublicacoes(repo.db, publicacoes, leitorID)
+}
+
+// BuscarPublicacoesDaLista retorna o feed de uma lista: as publicações dos membros e
+// as repostadas por eles, com as mesmas regras do feed principal
+func (repo RepositorioPublicacoes) BuscarPublicacoesDaLista(listaID, leitorID uint64) ([]modelos.Publicacao, error) {
+	return repo.buscarFeed(leitorID,
+		`exists (select 1 from lista_membros lm where lm.lista_id = ? and lm.usuario_id = p.autor_id)`,
+		[]interface{}{listaID},
+		`exists (select 1 from lista_membros lm where lm.lista_id = ? and lm.usuario_id = r.usuario_id)`,
+		[]interface{}{listaID})
 }
 
-// Atualizar atualiza uma publicação no banco de dados
+// Atualizar atualiza uma publicação no banco de dados, guardando a versão anterior como revisão.
+// Salvar o mesmo título e conteúdo não gera revisão. A visibilidade só muda se for informada
 func (repo RepositorioPublicacoes) Atualizar(publicacaoID uint64, publicacao modelos.Publicacao) error {
-	statement, erro := repo.db.Prepare("update publicacoes set titulo = ?, conteudo = ? where id = ?")
+	transacao, erro := repo.db.Begin()
 	if erro != nil {
 		return erro
 	}
 
-	defer statement.Close()
+	defer transacao.Rollback()
 
-	if _, erro = statement.Exec(publicacao.Titulo, publicacao.Conteudo, publicacaoID); erro != nil {
+	resultado, erro := transacao.Exec(`
+	insert into revisoes_publicacoes (publicacao_id, titulo, conteudo, criadaEm)
+	select id, titulo, conteudo, coalesce(editadaEm, criadaEm) from publicacoes
+	where id = ? and (binary titulo <> ? or binary conteudo <> ?)`,
+		publicacaoID, publicacao.Titulo, publicacao.Conteudo)
+	if erro != nil {
 		return erro
 	}
 
+	revisada, erro := resultado.RowsAffected()
+	if erro != nil {
+		return erro
+	}
+
+	if _, erro = transacao.Exec(`
+	update publicacoes set titulo = ?, conteudo = ?, visibilidade = coalesce(nullif(?, ''), visibilidade),
+	editadaEm = if(?, now(), editadaEm)
+	where id = ?`,
+		publicacao.Titulo, publicacao.Conteudo, publicacao.Visibilidade, revisada > 0, publicacaoID); erro != nil {
+		return erro
+	}
+
+	if erro = transacao.Commit(); erro != nil {
+		return erro
+	}
+
+	publicacao.ID = publicacaoID
+	busca.Padrao.IndexarPublicacao(publicacao)
+
 	return nil
 }
 
-// Deletar deleta uma publicação do banco de dados
+// BuscarRevisoes retorna as versões de uma publicação, da original até a atual
+func (repo RepositorioPublicacoes) BuscarRevisoes(publicacaoID uint64) ([]modelos.Revisao, error) {
+	linhas, erro := repo.db.Query(`
+	select titulo, conteudo, criadaEm from (
+		select id, titulo, conteudo, criadaEm from revisoes_publicacoes where publicacao_id = ?
+		union all
+		select null, titulo, conteudo, coalesce(editadaEm, criadaEm) from publicacoes where id = ?
+	) r
+	order by r.id is null, r.id`, publicacaoID, publicacaoID)
+
+	if erro != nil {
+		return nil, erro
+	}
+
+	defer linhas.Close()
+
+	var revisoes []modelos.Revisao
+
+	for linhas.Next() {
+		revisao := modelos.Revisao{Versao: uint64(len(revisoes) + 1)}
+
+		if erro = linhas.Scan(&revisao.Titulo, &revisao.Conteudo, &revisao.CriadaEm); erro != nil {
+			return nil, erro
+		}
+
+		revisoes = append(revisoes, revisao)
+	}
+
+	return revisoes, linhas.Err()
+}
+
+// Deletar marca uma publicação como excluída. Ela pode ser restaurada até o expurgo
 func (repo RepositorioPublicacoes) Deletar(publicacaoID uint64) error {
-	statement, erro := repo.db.Prepare("delete from publicacoes where id = ?")
+	statement, erro := repo.db.Prepare("update publicacoes set deletadaEm = now() where id = ? and deletadaEm is null")
 	if erro != nil {
 		return erro
 	}
@@ -119,42 +281,193 @@ func (repo RepositorioPublicacoes) Deletar(publicacaoID uint64) error {
 		return erro
 	}
 
+	busca.Padrao.RemoverPublicacao(publicacaoID)
+
 	return nil
 }
 
-// BuscarPublicacaoPorUsuario retorna todas as publicações de um usuário
-func (repo RepositorioPublicacoes) BuscarPublicacaoPorUsuario(usuarioID uint64) ([]modelos.Publicacao, error) {
+// Restaurar desfaz a exclusão de uma publicação do autor, desde que o prazo informado não tenha passado
+func (repo RepositorioPublicacoes) Restaurar(publicacaoID, autorID uint64, prazo time.Duration) (bool, error) {
+	resultado, erro := repo.db.Exec(`
+	update publicacoes set deletadaEm = null
+	where id = ? and autor_id = ? and deletadaEm > now() - interval ? second`,
+		publicacaoID, autorID, int64(prazo.Seconds()))
+	if erro != nil {
+		return false, erro
+	}
+
+	linhasAfetadas, erro := resultado.RowsAffected()
+	if erro != nil || linhasAfetadas == 0 {
+		return false, erro
+	}
+
+	publicacao, erro := repo.BuscarPublicacao(publicacaoID)
+	if erro != nil {
+		return false, erro
+	}
+
+	busca.Padrao.IndexarPublicacao(publicacao)
+
+	return true, nil
+}
+
+// Expurgar apaga definitivamente as publicações excluídas há mais tempo que o prazo
+func (repo RepositorioPublicacoes) Expurgar(prazo time.Duration) (int64, error) {
+	resultado, erro := repo.db.Exec(
+		"delete from publicacoes where deletadaEm <= now() - interval ? second", int64(prazo.Seconds()))
+	if erro != nil {
+		return 0, erro
+	}
+
+	return resultado.RowsAffected()
+}
+
+// BuscarRascunhos retorna os rascunhos e as publicações agendadas de um autor
+func (repo RepositorioPublicacoes) BuscarRascunhos(autorID uint64) ([]modelos.Publicacao, error) {
 	linhas, erro := repo.db.Query(`
-	select p.*, u.nick from publicacoes p 
-	inner join usuarios u on u.id = p.autor_id 
-	where p.autor_id= ?
-	`, usuarioID)
+	select `+colunasPublicacao+` from publicacoes p
+	inner join usuarios u on u.id = p.autor_id
+	where p.autor_id = ? and p.status <> 'publicada' and p.deletadaEm is null
+	order by p.status, p.publicarEm, p.id desc
+	`, autorID)
 
 	if erro != nil {
-		return []modelos.Publicacao{}, erro
+		return nil, erro
 	}
 
 	defer linhas.Close()
 
-	var publicacoes []modelos.Publicacao
+	publicacoes, erro := escanearPublicacoes(linhas)
+	if erro != nil {
+		return nil, erro
+	}
+
+	return publicacoes, completarPublicacoes(repo.db, publicacoes, autorID)
+}
+
+// AtualizarRascunho altera um rascunho ou publicação agendada, inclusive sua situação.
+// Retorna false se a publicação já tiver sido publicada
+func (repo RepositorioPublicacoes) AtualizarRascunho(publicacaoID uint64, publicacao modelos.Publicacao) (bool, error) {
+	resultado, erro := repo.db.Exec(`
+	update publicacoes set titulo = ?, conteudo = ?, status = ?, publicarEm = ?,
+	visibilidade = coalesce(nullif(?, ''), visibilidade), criadaEm = if(? = 'publicada', now(), criadaEm)
+	where id = ? and status <> 'publicada'`,
+		publicacao.Titulo, publicacao.Conteudo, publicacao.Status, publicacao.PublicarEm, publicacao.Visibilidade,
+		publicacao.Status, publicacaoID)
+	if erro != nil {
+		return false, erro
+	}
+
+	linhasAfetadas, erro := resultado.RowsAffected()
+	if erro != nil || linhasAfetadas == 0 {
+		return false, erro
+	}
+
+	publicacao.ID = publicacaoID
+	busca.Padrao.IndexarPublicacao(publicacao)
+
+	return true, nil
+}
+
+// BuscarAgendadasVencidas retorna as publicações agendadas cujo horário de publicação já chegou
+func (repo RepositorioPublicacoes) BuscarAgendadasVencidas() ([]uint64, error) {
+	linhas, erro := repo.db.Query(`
+	select id from publicacoes
+	where status = 'agendada' and publicarEm <= now() and deletadaEm is null
+	order by publicarEm`)
+	if erro != nil {
+		return nil, erro
+	}
+
+	defer linhas.Close()
+
+	var publicacoes []uint64
 
 	for linhas.Next() {
-		var publicacao modelos.Publicacao
+		var publicacaoID uint64
 
-		if erro = linhas.Scan(&publicacao.ID, &publicacao.Titulo, &publicacao.Conteudo, &publicacao.AutorID,
-			&publicacao.Curtidas, &publicacao.CriadaEm, &publicacao.AutorNick); erro != nil {
+		if erro = linhas.Scan(&publicacaoID); erro != nil {
 			return nil, erro
 		}
 
-		publicacoes = append(publicacoes, publicacao)
+		publicacoes = append(publicacoes, publicacaoID)
 	}
 
-	return publicacoes, nil
+	return publicacoes, linhas.Err()
 }
 
-// CurtirPublicacao adiciona uma curtida a publicação
-func (repo RepositorioPublicacoes) CurtirPublicacao(publicacaoID uint64) error {
-	statement, erro := repo.db.Prepare("update publicacoes set curtidas = curtidas + 1 where id = ?")
+// Publicar torna uma publicação agendada visível. Retorna false se ela já tiver sido publicada,
+// para que a divulgação aconteça uma única vez
+func (repo RepositorioPublicacoes) Publicar(publicacaoID uint64) (bool, error) {
+	resultado, erro := repo.db.Exec(`
+	update publicacoes set status = 'publicada', publicarEm = null, criadaEm = now()
+	where id = ? and status = 'agendada'`, publicacaoID)
+	if erro != nil {
+		return false, erro
+	}
+
+	linhasAfetadas, erro := resultado.RowsAffected()
+	return linhasAfetadas > 0, erro
+}
+
+// BuscarPublicacaoPorUsuario retorna todas as publicações de um usuário que o leitor pode ver,
+// com as fixadas primeiro e depois as mais recentes
+func (repo RepositorioPublicacoes) BuscarPublicacaoPorUsuario(usuarioID, leitorID uint64) ([]modelos.Publicacao, error) {
+	linhas, erro := repo.db.Query(`
+	select `+colunasPublicacao+` from publicacoes p
+	inner join usuarios u on u.id = p.autor_id 
+	where p.autor_id= ? and `+filtroLeitura+`
+	order by p.fixadaEm is null, p.fixadaEm desc, p.criadaEm desc, p.id desc
+	`, usuarioID, leitorID)
+
+	if erro != nil {
+		return []modelos.Publicacao{}, erro
+	}
+
+	defer linhas.Close()
+
+	publicacoes, erro := escanearPublicacoes(linhas)
+	if erro != nil {
+		return nil, erro
+	}
+
+	return publicacoes, completarPublicacoes(repo.db, publicacoes, leitorID)
+}
+
+// Fixar destaca uma publicação no perfil do autor. Retorna false se o autor já tiver
+// atingido o limite de publicações fixadas
+func (repo RepositorioPublicacoes) Fixar(publicacaoID, autorID uint64) (bool, error) {
+	transacao, erro := repo.db.Begin()
+	if erro != nil {
+		return false, erro
+	}
+
+	defer transacao.Rollback()
+
+	var fixadas int
+	if erro = transacao.QueryRow(`
+	select count(*) from publicacoes
+	where autor_id = ? and fixadaEm is not null and deletadaEm is null and id <> ?
+	for update`, autorID, publicacaoID).Scan(&fixadas); erro != nil {
+		return false, erro
+	}
+
+	if fixadas >= modelos.MaximoPublicacoesFixadas {
+		return false, nil
+	}
+
+	if _, erro = transacao.Exec(
+		"update publicacoes set fixadaEm = coalesce(fixadaEm, now()) where id = ? and autor_id = ?",
+		publicacaoID, autorID); erro != nil {
+		return false, erro
+	}
+
+	return true, transacao.Commit()
+}
+
+// Desfixar remove o destaque de uma publicação no perfil do autor
+func (repo RepositorioPublicacoes) Desfixar(publicacaoID uint64) error {
+	statement, erro := repo.db.Prepare("update publicacoes set fixadaEm = null where id = ?")
 	if erro != nil {
 		return erro
 	}
@@ -168,21 +481,502 @@ func (repo RepositorioPublicacoes) CurtirPublicacao(publicacaoID uint64) error {
 	return nil
 }
 
-// DescurtirPublicacao subtrai uma curtida da publicação
-func (repo RepositorioPublicacoes) DescurtirPublicacao(publicacaoID uint64) error {
-	statement, erro := repo.db.Prepare(`update publicacoes set curtidas = 
+// CurtirPublicacao registra a curtida do usuário na publicação. Cada usuário curte uma publicação
+// uma única vez: retorna false se ela já estava curtida, sem alterar o total nem as estatísticas
+func (repo RepositorioPublicacoes) CurtirPublicacao(publicacaoID, usuarioID uint64) (bool, error) {
+	transacao, erro := repo.db.Begin()
+	if erro != nil {
+		return false, erro
+	}
+
+	defer transacao.Rollback()
+
+	resultado, erro := transacao.Exec("insert ignore into curtidas (usuario_id, publicacao_id) values (?, ?)",
+		usuarioID, publicacaoID)
+	if erro != nil {
+		return false, erro
+	}
+
+	if linhasAfetadas, erro := resultado.RowsAffected(); erro != nil || linhasAfetadas == 0 {
+		return false, erro
+	}
+
+	if _, erro = transacao.Exec("update publicacoes set curtidas = curtidas + 1 where id = ?", publicacaoID); erro != nil {
+		return false, erro
+	}
+
+	if erro = registrarCurtida(transacao, publicacaoID, 1); erro != nil {
+		return false, erro
+	}
+
+	if erro = transacao.Commit(); erro != nil {
+		return false, erro
+	}
+
+	return true, nil
+}
+
+// DescurtirPublicacao remove a curtida do usuário na publicação. Retorna false se ela não estava curtida
+func (repo RepositorioPublicacoes) DescurtirPublicacao(publicacaoID, usuarioID uint64) (bool, error) {
+	transacao, erro := repo.db.Begin()
+	if erro != nil {
+		return false, erro
+	}
+
+	defer transacao.Rollback()
+
+	resultado, erro := transacao.Exec("delete from curtidas where usuario_id = ? and publicacao_id = ?",
+		usuarioID, publicacaoID)
+	if erro != nil {
+		return false, erro
+	}
+
+	if linhasAfetadas, erro := resultado.RowsAffected(); erro != nil || linhasAfetadas == 0 {
+		return false, erro
+	}
+
+	if _, erro = transacao.Exec(`update publicacoes set curtidas = 
 	CASE WHEN curtidas > 0 THEN curtidas - 1
 	ELSE 0 END
-	where id = ?`)
+	where id = ?`, publicacaoID); erro != nil {
+		return false, erro
+	}
+
+	if erro = registrarCurtida(transacao, publicacaoID, -1); erro != nil {
+		return false, erro
+	}
+
+	if erro = transacao.Commit(); erro != nil {
+		return false, erro
+	}
+
+	return true, nil
+}
+
+// escanearPublicacoes lê as linhas de uma consulta de publicações feita com colunasPublicacao
+func escanearPublicacoes(linhas *sql.Rows) ([]modelos.Publicacao, error) {
+	var publicacoes []modelos.Publicacao
+
+	for linhas.Next() {
+		publicacao, erro := escanearPublicacao(linhas)
+		if erro != nil {
+			return nil, erro
+		}
+
+		publicacoes = append(publicacoes, publicacao)
+	}
+
+	return publicacoes, linhas.Err()
+}
+
+// escanearPublicacao lê a linha atual de uma consulta feita com colunasPublicacao. As colunas
+// selecionadas depois de colunasPublicacao são lidas em extras
+func escanearPublicacao(linhas *sql.Rows, extras ...interface{}) (modelos.Publicacao, error) {
+	var publicacao modelos.Publicacao
+	var tags sql.NullString
+	var editadaEm, publicarEm sql.NullTime
+
+	destinos := append([]interface{}{&publicacao.ID, &publicacao.Titulo, &publicacao.Conteudo, &publicacao.AutorID,
+		&publicacao.Curtidas, &publicacao.Repostagens, &publicacao.Citacoes, &publicacao.Comentarios, &publicacao.CitacaoID,
+		&publicacao.Fixada, &publicacao.CriadaEm, &editadaEm, &publicacao.Status, &publicarEm, &publicacao.Visibilidade,
+		&publicacao.ComunidadeID, &publicacao.AutorNick, &tags}, extras...)
+
+	if erro := linhas.Scan(destinos...); erro != nil {
+		return modelos.Publicacao{}, erro
+	}
+
+	if publicarEm.Valid {
+		publicacao.PublicarEm = &publicarEm.Time
+	}
+
+	if editadaEm.Valid {
+		publicacao.EditadaEm = &editadaEm.Time
+		publicacao.Editada = true
+	}
+
+	if tags.Valid && tags.String != "" {
+		publicacao.Tags = strings.Split(tags.String, ",")
+	}
+
+	return publicacao, nil
+}
+
+// BuscarPublicacaoParaLeitor retorna uma publicação se o leitor tiver acesso a ela, com os dados
+// próprios do leitor preenchidos. Retorna uma publicação vazia caso contrário
+func (repo RepositorioPublicacoes) BuscarPublicacaoParaLeitor(publicacaoID, leitorID uint64) (modelos.Publicacao, error) {
+	linhas, erro := repo.db.Query(`
+	select `+colunasPublicacao+` from publicacoes p
+	inner join usuarios u on u.id = p.autor_id
+	where p.id = ? and `+filtroLeitura, publicacaoID, leitorID)
+
+	if erro != nil {
+		return modelos.Publicacao{}, erro
+	}
+
+	defer linhas.Close()
+
+	publicacoes, erro := escanearPublicacoes(linhas)
+	if erro != nil || len(publicacoes) == 0 {
+		return modelos.Publicacao{}, erro
+	}
+
+	if erro = completarPublicacoes(repo.db, publicacoes, leitorID); erro != nil {
+		return modelos.Publicacao{}, erro
+	}
+
+	return publicacoes[0], nil
+}
+
+// PodeVer indica se o leitor tem acesso a uma publicação
+func (repo RepositorioPublicacoes) PodeVer(publicacaoID, leitorID uint64) (bool, error) {
+	var pode bool
+	erro := repo.db.QueryRow(`
+	select exists (select 1 from publicacoes p where p.id = ? and `+filtroLeitura+`)`,
+		publicacaoID, leitorID).Scan(&pode)
+
+	return pode, erro
+}
+
+// Pesquisar busca as publicações que o leitor pode ver pelo título e conteúdo usando o buscador,
+// das mais relevantes para as menos. Publicações de usuários com bloqueio com o leitor não aparecem.
+// Os candidatos são pedidos ao buscador em lotes e filtrados até completar a página, já que o
+// buscador não conhece as regras de acesso nem os filtros
+func (repo RepositorioPublicacoes) Pesquisar(filtro modelos.FiltroBusca, leitorID, limite, deslocamento uint64) ([]modelos.ResultadoBusca, error) {
+	necessarias := int(deslocamento + limite)
+
+	var publicacoes []modelos.Publicacao
+	relevancias := make(map[uint64]float64)
+
+	for inicio := 0; len(publicacoes) < necessarias; inicio += loteCandidatosPublicacoes {
+		encontradas := busca.Padrao.BuscarPublicacoes(filtro.Termo, loteCandidatosPublicacoes, inicio)
+		if len(encontradas) == 0 {
+			break
+		}
+
+		visiveis, erro := repo.filtrarPesquisa(encontradas, filtro, leitorID)
+		if erro != nil {
+			return nil, erro
+		}
+
+		for _, encontrada := range encontradas {
+			if publicacao, existe := visiveis[encontrada.ID]; existe {
+				publicacoes = append(publicacoes, publicacao)
+				relevancias[encontrada.ID] = encontrada.Relevancia
+			}
+		}
+
+		if len(encontradas) < loteCandidatosPublicacoes {
+			break
+		}
+	}
+
+	if deslocamento >= uint64(len(publicacoes)) {
+		return nil, nil
+	}
+
+	publicacoes = publicacoes[deslocamento:]
+	if uint64(len(publicacoes)) > limite {
+		publicacoes = publicacoes[:limite]
+	}
+
+	if erro := completarPublicacoes(repo.db, publicacoes, leitorID); erro != nil {
+		return nil, erro
+	}
+
+	termos := modelos.ExtrairTermos(filtro.Termo)
+	resultados := make([]modelos.ResultadoBusca, 0, len(publicacoes))
+	for _, publicacao := range publicacoes {
+		resultados = append(resultados, modelos.ResultadoBusca{
+			Publicacao: publicacao,
+			Relevancia: relevancias[publicacao.ID],
+			Destaques: modelos.Destaques{
+				Titulo:   modelos.Destacar(publicacao.Titulo, termos, 0),
+				Conteudo: modelos.Destacar(publicacao.Conteudo, termos, modelos.TamanhoDestaque),
+			},
+		})
+	}
+
+	return resultados, nil
+}
+
+// filtrarPesquisa retorna, entre as publicações encontradas pelo buscador, as que atendem aos
+// filtros da busca e que o leitor pode ver, indexadas pelo ID
+func (repo RepositorioPublicacoes) filtrarPesquisa(encontradas []busca.Resultado, filtro modelos.FiltroBusca, leitorID uint64) (map[uint64]modelos.Publicacao, error) {
+	parametros := make([]interface{}, 0, len(encontradas)+11)
+	for _, encontrada := range encontradas {
+		parametros = append(parametros, encontrada.ID)
+	}
+	parametros = append(parametros, filtro.AutorNick, filtro.AutorNick, filtro.Tag, filtro.Tag,
+		filtro.De, filtro.De, filtro.Ate, filtro.Ate, leitorID, leitorID, leitorID)
+
+	linhas, erro := repo.db.Query(`
+	select `+colunasPublicacao+`
+	from publicacoes p
+	inner join usuarios u on u.id = p.autor_id
+	where p.id in (`+marcadores(len(encontradas))+`)
+	and (? = '' or u.nick = ?)
+	and (? = '' or exists (
+		select 1 from publicacao_tags pt inner join tags t on t.id = pt.tag_id
+		where pt.publicacao_id = p.id and t.nome = ?))
+	and (? is null or p.criadaEm >= ?)
+	and (? is null or p.criadaEm < ?)
+	and not exists (
+		select 1 from bloqueios b
+		where (b.usuario_id = ? and b.bloqueado_id = p.autor_id) or (b.usuario_id = p.autor_id and b.bloqueado_id = ?)
+	)
+	and `+filtroLeitura, parametros...)
+	if erro != nil {
+		return nil, erro
+	}
+
+	defer linhas.Close()
+
+	publicacoes, erro := escanearPublicacoes(linhas)
 	if erro != nil {
+		return nil, erro
+	}
+
+	visiveis := make(map[uint64]modelos.Publicacao, len(publicacoes))
+	for _, publicacao := range publicacoes {
+		visiveis[publicacao.ID] = publicacao
+	}
+
+	return visiveis, nil
+}
+
+// BuscarAudiencia retorna os usuários que recebem a publicação no feed: o autor,
+// seus seguidores e quem segue alguma das tags da publicação, desde que tenham acesso a ela
+// pela comunidade e pela visibilidade escolhida
+func (repo RepositorioPublicacoes) BuscarAudiencia(publicacao modelos.Publicacao) ([]uint64, error) {
+	linhas, erro := repo.db.Query(`
+	select a.usuario_id from (
+		select seguidor_id as usuario_id from seguidores where usuario_id = ?
+		union
+		select ts.usuario_id from tags_seguidas ts
+		inner join publicacao_tags pt on pt.tag_id = ts.tag_id
+		where pt.publicacao_id = ?
+		and not exists (
+			select 1 from bloqueios b
+			where (b.usuario_id = ts.usuario_id and b.bloqueado_id = ?)
+			or (b.usuario_id = ? and b.bloqueado_id = ts.usuario_id)
+		)
+	) a
+	inner join publicacoes p on p.id = ?
+	where (p.comunidade_id is null
+	or exists (select 1 from comunidades c where c.id = p.comunidade_id and c.privada = false)
+	or exists (select 1 from comunidade_membros cm where cm.comunidade_id = p.comunidade_id and cm.usuario_id = a.usuario_id))
+	and (p.visibilidade = 'publico'
+	or (p.visibilidade = 'seguidores'
+		and exists (select 1 from seguidores s where s.usuario_id = p.autor_id and s.seguidor_id = a.usuario_id))
+	or (p.visibilidade = 'mencionados'
+		and exists (select 1 from mencoes m where m.publicacao_id = p.id and m.usuario_id = a.usuario_id)))
+	`, publicacao.AutorID, publicacao.ID, publicacao.AutorID, publicacao.AutorID, publicacao.ID)
+	if erro != nil {
+		return nil, erro
+	}
+
+	defer linhas.Close()
+
+	usuarios := []uint64{publicacao.AutorID}
+
+	for linhas.Next() {
+		var usuarioID uint64
+
+		if erro = linhas.Scan(&usuarioID); erro != nil {
+			return nil, erro
+		}
+
+		usuarios = append(usuarios, usuarioID)
+	}
+
+	return usuarios, linhas.Err()
+}
+
+// completarPublicacoes carrega os dados associados às publicações que não vêm na consulta principal,
+// inclusive os que dependem de quem está lendo. Com leitorID 0 esses últimos não são carregados
+func completarPublicacoes(db *sql.DB, publicacoes []modelos.Publicacao, leitorID uint64) error {
+	if erro := carregarMencoes(db, publicacoes); erro != nil {
 		return erro
 	}
 
-	defer statement.Close()
+	if erro := carregarCitacoes(db, publicacoes); erro != nil {
+		return erro
+	}
 
-	if _, erro = statement.Exec(publicacaoID); erro != nil {
+	if erro := carregarEnquetes(db, publicacoes, leitorID); erro != nil {
 		return erro
 	}
 
+	if erro := carregarReacoes(db, publicacoes); erro != nil {
+		return erro
+	}
+
+	if leitorID == 0 {
+		return nil
+	}
+
+	return carregarDadosDoLeitor(db, publicacoes, leitorID)
+}
+
+// carregarDadosDoLeitor preenche as informações das publicações que são próprias do leitor
+func carregarDadosDoLeitor(db *sql.DB, publicacoes []modelos.Publicacao, leitorID uint64) error {
+	if len(publicacoes) == 0 {
+		return nil
+	}
+
+	indices := make(map[uint64][]int, len(publicacoes))
+	ids := make([]interface{}, 0, len(publicacoes)+1)
+	ids = append(ids, leitorID)
+	for i, publicacao := range publicacoes {
+		indices[publicacao.ID] = append(indices[publicacao.ID], i)
+		ids = append(ids, publicacao.ID)
+	}
+
+	linhas, erro := db.Query(`
+	select publicacao_id from salvos
+	where usuario_id = ? and publicacao_id in (`+marcadores(len(ids)-1)+`)`, ids...)
+	if erro != nil {
+		return erro
+	}
+
+	defer linhas.Close()
+
+	for linhas.Next() {
+		var publicacaoID uint64
+
+		if erro = linhas.Scan(&publicacaoID); erro != nil {
+			return erro
+		}
+
+		for _, i := range indices[publicacaoID] {
+			publicacoes[i].SalvoPorMim = true
+		}
+	}
+
+	if erro = linhas.Err(); erro != nil {
+		return erro
+	}
+
+	linhasReacoes, erro := db.Query(`
+	select publicacao_id, tipo from reacoes
+	where usuario_id = ? and publicacao_id in (`+marcadores(len(ids)-1)+`)`, ids...)
+	if erro != nil {
+		return erro
+	}
+
+	defer linhasReacoes.Close()
+
+	for linhasReacoes.Next() {
+		var publicacaoID uint64
+		var tipo string
+
+		if erro = linhasReacoes.Scan(&publicacaoID, &tipo); erro != nil {
+			return erro
+		}
+
+		for _, i := range indices[publicacaoID] {
+			publicacoes[i].MinhaReacao = tipo
+		}
+	}
+
+	return linhasReacoes.Err()
+}
+
+// carregarCitacoes preenche as publicações citadas. Só são exibidas as citações que qualquer
+// usuário pode ver; as excluídas ou que deixaram de ser públicas ficam marcadas como indisponíveis
+func carregarCitacoes(db *sql.DB, publicacoes []modelos.Publicacao) error {
+	var ids []interface{}
+	for _, publicacao := range publicacoes {
+		if publicacao.CitacaoID != 0 {
+			ids = append(ids, publicacao.CitacaoID)
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	// o leitor 0 não existe, então filtroLeitura só deixa passar o que é público
+	linhas, erro := db.Query(`
+	select `+colunasPublicacao+` from publicacoes p
+	inner join usuarios u on u.id = p.autor_id
+	where p.id in (`+marcadores(len(ids))+`) and `+filtroLeitura,
+		append(ids, 0)...)
+	if erro != nil {
+		return erro
+	}
+
+	defer linhas.Close()
+
+	citadas, erro := escanearPublicacoes(linhas)
+	if erro != nil {
+		return erro
+	}
+
+	if erro = carregarMencoes(db, citadas); erro != nil {
+		return erro
+	}
+
+	porID := make(map[uint64]modelos.Publicacao, len(citadas))
+	for _, citada := range citadas {
+		porID[citada.ID] = citada
+	}
+
+	for i := range publicacoes {
+		if publicacoes[i].CitacaoID == 0 {
+			continue
+		}
+
+		if citada, encontrada := porID[publicacoes[i].CitacaoID]; encontrada {
+			publicacoes[i].Citacao = &citada
+		} else {
+			publicacoes[i].CitacaoIndisponivel = true
+		}
+	}
+
 	return nil
 }
+
+// carregarMencoes preenche as menções das publicações
+func carregarMencoes(db *sql.DB, publicacoes []modelos.Publicacao) error {
+	if len(publicacoes) == 0 {
+		return nil
+	}
+
+	indices := make(map[uint64]int, len(publicacoes))
+	ids := make([]interface{}, 0, len(publicacoes))
+	for i, publicacao := range publicacoes {
+		indices[publicacao.ID] = i
+		ids = append(ids, publicacao.ID)
+	}
+
+	linhas, erro := db.Query(`
+	select m.publicacao_id, m.usuario_id, u.nick, m.campo, m.inicio, m.tamanho from mencoes m
+	inner join usuarios u on u.id = m.usuario_id
+	where m.publicacao_id in (`+marcadores(len(ids))+`) and u.deletadoEm is null
+	order by m.publicacao_id, m.campo desc, m.inicio`, ids...)
+	if erro != nil {
+		return erro
+	}
+
+	defer linhas.Close()
+
+	for linhas.Next() {
+		var publicacaoID uint64
+		var mencao modelos.Mencao
+
+		if erro = linhas.Scan(&publicacaoID, &mencao.UsuarioID, &mencao.Nick, &mencao.Campo, &mencao.Inicio, &mencao.Tamanho); erro != nil {
+			return erro
+		}
+
+		publicacao := &publicacoes[indices[publicacaoID]]
+		publicacao.Mencoes = append(publicacao.Mencoes, mencao)
+	}
+
+	return linhas.Err()
+}
+
+// marcadores retorna n marcadores "?" separados por vírgula para cláusulas in
+func marcadores(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
@@ -0,0 +1,128 @@
+package repositorios
+
+import (
+	"api/src/modelos"
+	"database/sql"
+)
+
+// RepositorioReacoes representa um repositorio de reações a publicações
+type RepositorioReacoes struct {
+	db *sql.DB
+}
+
+// NovoRepositorioDeReacoes cria um repositorio de reações
+func NovoRepositorioDeReacoes(db *sql.DB) *RepositorioReacoes {
+	return &RepositorioReacoes{db}
+}
+
+// Reagir registra a reação do usuário à publicação, substituindo a que ele já tinha deixado
+func (repo RepositorioReacoes) Reagir(publicacaoID, usuarioID uint64, tipo string) error {
+	statement, erro := repo.db.Prepare(`
+	insert into reacoes (usuario_id, publicacao_id, tipo) values (?, ?, ?)
+	on duplicate key update tipo = values(tipo), criadaEm = current_timestamp()`)
+	if erro != nil {
+		return erro
+	}
+
+	defer statement.Close()
+
+	if _, erro = statement.Exec(usuarioID, publicacaoID, tipo); erro != nil {
+		return erro
+	}
+
+	return nil
+}
+
+// RemoverReacao remove a reação do usuário à publicação
+func (repo RepositorioReacoes) RemoverReacao(publicacaoID, usuarioID uint64) error {
+	statement, erro := repo.db.Prepare("delete from reacoes where usuario_id = ? and publicacao_id = ?")
+	if erro != nil {
+		return erro
+	}
+
+	defer statement.Close()
+
+	if _, erro = statement.Exec(usuarioID, publicacaoID); erro != nil {
+		return erro
+	}
+
+	return nil
+}
+
+// BuscarReacoes retorna quem reagiu à publicação, opcionalmente apenas com um tipo de reação,
+// das reações mais recentes para as mais antigas. Usuários com bloqueio com o leitor não aparecem
+func (repo RepositorioReacoes) BuscarReacoes(publicacaoID, leitorID uint64, tipo string, limite, deslocamento uint64) ([]modelos.Reacao, error) {
+	linhas, erro := repo.db.Query(`
+	select r.tipo, r.usuario_id, u.nick, r.criadaEm from reacoes r
+	inner join usuarios u on u.id = r.usuario_id
+	where r.publicacao_id = ? and (? = '' or r.tipo = ?) and u.deletadoEm is null
+	and not exists (
+		select 1 from bloqueios b
+		where (b.usuario_id = ? and b.bloqueado_id = r.usuario_id)
+		or (b.usuario_id = r.usuario_id and b.bloqueado_id = ?)
+	)
+	order by r.criadaEm desc, r.usuario_id
+	limit ? offset ?`, publicacaoID, tipo, tipo, leitorID, leitorID, limite, deslocamento)
+	if erro != nil {
+		return nil, erro
+	}
+
+	defer linhas.Close()
+
+	var reacoes []modelos.Reacao
+
+	for linhas.Next() {
+		var reacao modelos.Reacao
+
+		if erro = linhas.Scan(&reacao.Tipo, &reacao.UsuarioID, &reacao.UsuarioNick, &reacao.CriadaEm); erro != nil {
+			return nil, erro
+		}
+
+		reacoes = append(reacoes, reacao)
+	}
+
+	return reacoes, linhas.Err()
+}
+
+// carregarReacoes preenche a contagem de cada tipo de reação das publicações
+func carregarReacoes(db *sql.DB, publicacoes []modelos.Publicacao) error {
+	if len(publicacoes) == 0 {
+		return nil
+	}
+
+	indices := make(map[uint64][]int, len(publicacoes))
+	ids := make([]interface{}, 0, len(publicacoes))
+	for i, publicacao := range publicacoes {
+		indices[publicacao.ID] = append(indices[publicacao.ID], i)
+		ids = append(ids, publicacao.ID)
+	}
+
+	linhas, erro := db.Query(`
+	select r.publicacao_id, r.tipo, count(*) from reacoes r
+	inner join usuarios u on u.id = r.usuario_id
+	where r.publicacao_id in (`+marcadores(len(ids))+`) and u.deletadoEm is null
+	group by r.publicacao_id, r.tipo`, ids...)
+	if erro != nil {
+		return erro
+	}
+
+	defer linhas.Close()
+
+	for linhas.Next() {
+		var publicacaoID, quantidade uint64
+		var tipo string
+
+		if erro = linhas.Scan(&publicacaoID, &tipo, &quantidade); erro != nil {
+			return erro
+		}
+
+		for _, i := range indices[publicacaoID] {
+			if publicacoes[i].Reacoes == nil {
+				publicacoes[i].Reacoes = make(map[string]uint64)
+			}
+			publicacoes[i].Reacoes[tipo] = quantidade
+		}
+	}
+
+	return linhas.Err()
+}
@@ -0,0 +1,81 @@
+package repositorios
+
+import (
+	"database/sql"
+)
+
+// RepositorioRepostagens representa um repositorio de repostagens
+type RepositorioRepostagens struct {
+	db *sql.DB
+}
+
+// NovoRepositorioDeRepostagens cria um repositorio de repostagens
+func NovoRepositorioDeRepostagens(db *sql.DB) *RepositorioRepostagens {
+	return &RepositorioRepostagens{db}
+}
+
+// Repostar registra que o usuário repostou a publicação. Retorna false se ele já a tinha repostado
+func (repo RepositorioRepostagens) Repostar(publicacaoID, usuarioID uint64) (bool, error) {
+	statement, erro := repo.db.Prepare("insert ignore into repostagens (usuario_id, publicacao_id) values (?, ?)")
+	if erro != nil {
+		return false, erro
+	}
+
+	defer statement.Close()
+
+	resultado, erro := statement.Exec(usuarioID, publicacaoID)
+	if erro != nil {
+		return false, erro
+	}
+
+	linhasAfetadas, erro := resultado.RowsAffected()
+	return linhasAfetadas > 0, erro
+}
+
+// DesfazerRepostagem remove a repostagem de uma publicação feita pelo usuário
+func (repo RepositorioRepostagens) DesfazerRepostagem(publicacaoID, usuarioID uint64) error {
+	statement, erro := repo.db.Prepare("delete from repostagens where usuario_id = ? and publicacao_id = ?")
+	if erro != nil {
+		return erro
+	}
+
+	defer statement.Close()
+
+	if _, erro = statement.Exec(usuarioID, publicacaoID); erro != nil {
+		return erro
+	}
+
+	return nil
+}
+
+// BuscarAudiencia retorna quem recebe a repostagem no feed: o próprio usuário e seus
+// seguidores, exceto os que têm bloqueio com o autor da publicação
+func (repo RepositorioRepostagens) BuscarAudiencia(usuarioID, autorID uint64) ([]uint64, error) {
+	linhas, erro := repo.db.Query(`
+	select s.seguidor_id from seguidores s
+	where s.usuario_id = ?
+	and not exists (
+		select 1 from bloqueios b
+		where (b.usuario_id = s.seguidor_id and b.bloqueado_id = ?)
+		or (b.usuario_id = ? and b.bloqueado_id = s.seguidor_id)
+	)`, usuarioID, autorID, autorID)
+	if erro != nil {
+		return nil, erro
+	}
+
+	defer linhas.Close()
+
+	usuarios := []uint64{usuarioID}
+
+	for linhas.Next() {
+		var seguidorID uint64
+
+		if erro = linhas.Scan(&seguidorID); erro != nil {
+			return nil, erro
+		}
+
+		usuarios = append(usuarios, seguidorID)
+	}
+
+	return usuarios, linhas.Err()
+}
@@ -0,0 +1,138 @@
+package repositorios
+
+import (
+	"api/src/modelos"
+	"database/sql"
+)
+
+// RepositorioSalvos representa um repositorio de publicações salvas e coleções
+type RepositorioSalvos struct {
+	db *sql.DB
+}
+
+// NovoRepositorioDeSalvos cria um repositorio de publicações salvas
+func NovoRepositorioDeSalvos(db *sql.DB) *RepositorioSalvos {
+	return &RepositorioSalvos{db}
+}
+
+// Salvar guarda uma publicação para o usuário, na coleção informada (criada se ainda não existir)
+// ou fora de coleções se o nome for vazio. Salvar de novo move a publicação de coleção
+func (repo RepositorioSalvos) Salvar(usuarioID, publicacaoID uint64, colecao string) error {
+	transacao, erro := repo.db.Begin()
+	if erro != nil {
+		return erro
+	}
+
+	defer transacao.Rollback()
+
+	var colecaoID sql.NullInt64
+	if colecao != "" {
+		if _, erro = transacao.Exec(
+			"insert ignore into colecoes (usuario_id, nome) values (?, ?)", usuarioID, colecao); erro != nil {
+			return erro
+		}
+
+		if erro = transacao.QueryRow(
+			"select id from colecoes where usuario_id = ? and nome = ?", usuarioID, colecao).Scan(&colecaoID); erro != nil {
+			return erro
+		}
+	}
+
+	if _, erro = transacao.Exec(`
+	insert into salvos (usuario_id, publicacao_id, colecao_id) values (?, ?, ?)
+	on duplicate key update colecao_id = values(colecao_id)`,
+		usuarioID, publicacaoID, colecaoID); erro != nil {
+		return erro
+	}
+
+	return transacao.Commit()
+}
+
+// Remover tira uma publicação dos salvos do usuário
+func (repo RepositorioSalvos) Remover(usuarioID, publicacaoID uint64) error {
+	statement, erro := repo.db.Prepare("delete from salvos where usuario_id = ? and publicacao_id = ?")
+	if erro != nil {
+		return erro
+	}
+
+	defer statement.Close()
+
+	if _, erro = statement.Exec(usuarioID, publicacaoID); erro != nil {
+		return erro
+	}
+
+	return nil
+}
+
+// Buscar retorna as publicações salvas pelo usuário que ele ainda pode ver, das salvas mais
+// recentemente para as mais antigas, opcionalmente só as de uma coleção
+func (repo RepositorioSalvos) Buscar(usuarioID uint64, colecao string, limite, deslocamento uint64) ([]modelos.Publicacao, error) {
+	linhas, erro := repo.db.Query(`
+	select `+colunasPublicacao+` from salvos s
+	inner join publicacoes p on p.id = s.publicacao_id
+	inner join usuarios u on u.id = p.autor_id
+	left join colecoes c on c.id = s.colecao_id
+	where s.usuario_id = ? and (? = '' or c.nome = ?)
+	and `+filtroLeitura+`
+	order by s.criadoEm desc, p.id desc
+	limit ? offset ?`, usuarioID, colecao, colecao, usuarioID, limite, deslocamento)
+
+	if erro != nil {
+		return nil, erro
+	}
+
+	defer linhas.Close()
+
+	publicacoes, erro := escanearPublicacoes(linhas)
+	if erro != nil {
+		return nil, erro
+	}
+
+	return publicacoes, completarPublicacoes(repo.db, publicacoes, usuarioID)
+}
+
+// BuscarColecoes retorna as coleções do usuário com a quantidade de publicações em cada uma
+func (repo RepositorioSalvos) BuscarColecoes(usuarioID uint64) ([]modelos.Colecao, error) {
+	linhas, erro := repo.db.Query(`
+	select c.id, c.nome, c.criadaEm,
+	(select count(*) from salvos s where s.colecao_id = c.id) as total
+	from colecoes c
+	where c.usuario_id = ?
+	order by c.nome`, usuarioID)
+
+	if erro != nil {
+		return nil, erro
+	}
+
+	defer linhas.Close()
+
+	var colecoes []modelos.Colecao
+
+	for linhas.Next() {
+		var colecao modelos.Colecao
+
+		if erro = linhas.Scan(&colecao.ID, &colecao.Nome, &colecao.CriadaEm, &colecao.Total); erro != nil {
+			return nil, erro
+		}
+
+		colecoes = append(colecoes, colecao)
+	}
+
+	return colecoes, nil
+}
+
+// DeletarColecao apaga uma coleção do usuário. As publicações dela continuam salvas, fora de coleções
+func (repo RepositorioSalvos) DeletarColecao(usuarioID, colecaoID uint64) error {
+	statement, erro := repo.db.Prepare("delete from colecoes where id = ? and usuario_id = ?")
+	if erro != nil {
+		return erro
+	}
+
+	defer statement.Close()
+
+	if _, erro = statement.Exec(colecaoID, usuarioID); erro != nil {
+		return erro
+	}
+
+	return nil
+}
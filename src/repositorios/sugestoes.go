@@ -0,0 +1,53 @@
+package repositorios
+
+import "api/src/modelos"
+
+// BuscarSugestoes calcula quem o usuário pode querer seguir: quem é seguido por quem ele segue,
+// quem segue as mesmas tags e, por último, os usuários mais seguidos. Não aparecem os usuários
+// que ele já segue nem os que têm bloqueio com ele
+func (u Repositorio) BuscarSugestoes(usuarioID, limite uint64) ([]modelos.Sugestao, error) {
+	linhas, erro := u.db.Query(`
+	select c.id, c.nome, c.nick, c.amigos, coalesce(c.amigo_nick, ''), c.tags, c.seguidores from (
+		select u.id, u.nome, u.nick,
+		(select count(*) from seguidores s
+			inner join seguidores meus on meus.usuario_id = s.seguidor_id and meus.seguidor_id = ?
+			inner join usuarios m on m.id = s.seguidor_id and m.deletadoEm is null
+			where s.usuario_id = u.id) as amigos,
+		(select m.nick from seguidores s
+			inner join seguidores meus on meus.usuario_id = s.seguidor_id and meus.seguidor_id = ?
+			inner join usuarios m on m.id = s.seguidor_id and m.deletadoEm is null
+			where s.usuario_id = u.id order by m.id limit 1) as amigo_nick,
+		(select count(*) from tags_seguidas t
+			inner join tags_seguidas minhas on minhas.tag_id = t.tag_id and minhas.usuario_id = ?
+			where t.usuario_id = u.id) as tags,
+		(select count(*) from seguidores s where s.usuario_id = u.id) as seguidores
+		from usuarios u
+		where u.id <> ? and `+filtroContaAtiva+` and `+filtroSemBloqueio+`
+		and not exists (select 1 from seguidores s where s.usuario_id = u.id and s.seguidor_id = ?)
+	) c
+	where c.amigos > 0 or c.tags > 0 or c.seguidores > 0
+	order by c.amigos * 3 + c.tags * 2 desc, c.seguidores desc, c.id
+	limit ?`,
+		usuarioID, usuarioID, usuarioID, usuarioID, usuarioID, usuarioID, usuarioID, limite)
+	if erro != nil {
+		return nil, erro
+	}
+
+	defer linhas.Close()
+
+	var sugestoes []modelos.Sugestao
+
+	for linhas.Next() {
+		var sugestao modelos.Sugestao
+
+		if erro = linhas.Scan(&sugestao.Usuario.ID, &sugestao.Usuario.Nome, &sugestao.Usuario.Nick,
+			&sugestao.AmigosEmComum, &sugestao.AmigoNick, &sugestao.TagsEmComum, &sugestao.Seguidores); erro != nil {
+			return nil, erro
+		}
+
+		sugestao.FormatarMotivo()
+		sugestoes = append(sugestoes, sugestao)
+	}
+
+	return sugestoes, linhas.Err()
+}
@@ -0,0 +1,137 @@
+package repositorios
+
+import (
+	"api/src/modelos"
+	"database/sql"
+)
+
+// RepositorioTags representa um repositorio de tags
+type RepositorioTags struct {
+	db *sql.DB
+}
+
+// NovoRepositorioDeTags cria um repositorio de tags
+func NovoRepositorioDeTags(db *sql.DB) *RepositorioTags {
+	return &RepositorioTags{db}
+}
+
+// SalvarTagsDaPublicacao substitui as tags associadas a uma publicação
+func (repo RepositorioTags) SalvarTagsDaPublicacao(publicacaoID uint64, tags []string) error {
+	if _, erro := repo.db.Exec("delete from publicacao_tags where publicacao_id = ?", publicacaoID); erro != nil {
+		return erro
+	}
+
+	for _, tag := range tags {
+		if erro := repo.criarTag(tag); erro != nil {
+			return erro
+		}
+
+		if _, erro := repo.db.Exec(`
+		insert ignore into publicacao_tags (publicacao_id, tag_id)
+		select ?, id from tags where nome = ?`, publicacaoID, tag); erro != nil {
+			return erro
+		}
+	}
+
+	return nil
+}
+
+// BuscarPublicacoesPorTag retorna as publicações que contém uma tag e que o leitor pode ver
+func (repo RepositorioTags) BuscarPublicacoesPorTag(tag string, leitorID uint64) ([]modelos.Publicacao, error) {
+	linhas, erro := repo.db.Query(`
+	select `+colunasPublicacao+` from publicacoes p
+	inner join usuarios u on u.id = p.autor_id
+	inner join publicacao_tags pt on pt.publicacao_id = p.id
+	inner join tags t on t.id = pt.tag_id
+	where t.nome = ? and `+filtroLeitura+`
+	order by p.criadaEm desc, p.id desc
+	`, tag, leitorID)
+
+	if erro != nil {
+		return nil, erro
+	}
+
+	defer linhas.Close()
+
+	publicacoes, erro := escanearPublicacoes(linhas)
+	if erro != nil {
+		return nil, erro
+	}
+
+	return publicacoes, completarPublicacoes(repo.db, publicacoes, leitorID)
+}
+
+// Seguir permite que um usuário siga uma tag
+func (repo RepositorioTags) Seguir(usuarioID uint64, tag string) error {
+	if erro := repo.criarTag(tag); erro != nil {
+		return erro
+	}
+
+	statement, erro := repo.db.Prepare(`
+	insert ignore into tags_seguidas (usuario_id, tag_id)
+	select ?, id from tags where nome = ?`)
+	if erro != nil {
+		return erro
+	}
+
+	defer statement.Close()
+
+	if _, erro = statement.Exec(usuarioID, tag); erro != nil {
+		return erro
+	}
+
+	return nil
+}
+
+// DeixarDeSeguir permite que um usuário deixe de seguir uma tag
+func (repo RepositorioTags) DeixarDeSeguir(usuarioID uint64, tag string) error {
+	statement, erro := repo.db.Prepare(`
+	delete ts from tags_seguidas ts
+	inner join tags t on t.id = ts.tag_id
+	where ts.usuario_id = ? and t.nome = ?`)
+	if erro != nil {
+		return erro
+	}
+
+	defer statement.Close()
+
+	if _, erro = statement.Exec(usuarioID, tag); erro != nil {
+		return erro
+	}
+
+	return nil
+}
+
+// BuscarTagsSeguidas retorna as tags que um usuário segue
+func (repo RepositorioTags) BuscarTagsSeguidas(usuarioID uint64) ([]string, error) {
+	linhas, erro := repo.db.Query(`
+	select t.nome from tags t
+	inner join tags_seguidas ts on ts.tag_id = t.id
+	where ts.usuario_id = ?
+	order by t.nome`, usuarioID)
+
+	if erro != nil {
+		return nil, erro
+	}
+
+	defer linhas.Close()
+
+	var tags []string
+
+	for linhas.Next() {
+		var tag string
+
+		if erro = linhas.Scan(&tag); erro != nil {
+			return nil, erro
+		}
+
+		tags = append(tags, tag)
+	}
+
+	return tags, nil
+}
+
+func (repo RepositorioTags) criarTag(tag string) error {
+	_, erro := repo.db.Exec("insert ignore into tags (nome) values (?)", tag)
+	return erro
+}
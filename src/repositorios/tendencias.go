@@ -0,0 +1,187 @@
+package repositorios
+
+import (
+	"api/src/modelos"
+	"database/sql"
+	"time"
+)
+
+// limiteTendencias é quantas publicações e tags são guardadas em cada janela
+const limiteTendencias = 100
+
+// decaimento é o peso de um evento ocorrido no momento da coluna: 1 agora, caindo pela metade
+// a cada meia-vida (único parâmetro, em segundos)
+func decaimento(coluna string) string {
+	return `pow(0.5, timestampdiff(second, ` + coluna + `, now()) / ?)`
+}
+
+// RepositorioTendencias representa um repositorio de tendências
+type RepositorioTendencias struct {
+	db *sql.DB
+}
+
+// NovoRepositorioDeTendencias cria um repositorio de tendências
+func NovoRepositorioDeTendencias(db *sql.DB) *RepositorioTendencias {
+	return &RepositorioTendencias{db}
+}
+
+// Calcular substitui as tendências da janela. Cada publicação pública soma, dentro da janela,
+// 1 ponto por curtida e por reação, 2 por comentário, 2 por repostagem e 2 por citação, com peso
+// menor quanto mais antigo o evento (a meia-vida é um quarto da janela). As tags somam a
+// pontuação das publicações em alta e o uso recente em publicações públicas
+func (repo RepositorioTendencias) Calcular(janela string, duracao time.Duration) error {
+	segundos := int64(duracao.Seconds())
+	meiaVida := segundos / 4
+
+	transacao, erro := repo.db.Begin()
+	if erro != nil {
+		return erro
+	}
+
+	defer transacao.Rollback()
+
+	if _, erro = transacao.Exec("delete from tendencias_publicacoes where janela = ?", janela); erro != nil {
+		return erro
+	}
+
+	if _, erro = transacao.Exec(`
+	insert into tendencias_publicacoes (janela, publicacao_id, pontuacao)
+	select ?, c.id, c.pontuacao from (
+		select p.id,
+		(select coalesce(sum(`+decaimento("cu.criadaEm")+`), 0) from curtidas cu
+			where cu.publicacao_id = p.id and cu.criadaEm >= now() - interval ? second)
+		+ (select coalesce(sum(`+decaimento("r.criadaEm")+`), 0) from reacoes r
+			where r.publicacao_id = p.id and r.criadaEm >= now() - interval ? second)
+		+ 2 * (select coalesce(sum(`+decaimento("co.criadoEm")+`), 0) from comentarios co
+			where co.publicacao_id = p.id and co.criadoEm >= now() - interval ? second)
+		+ 2 * (select coalesce(sum(`+decaimento("r.criadaEm")+`), 0) from repostagens r
+			where r.publicacao_id = p.id and r.criadaEm >= now() - interval ? second)
+		+ 2 * (select coalesce(sum(`+decaimento("q.criadaEm")+`), 0) from publicacoes q
+			where q.citacao_id = p.id and q.status = 'publicada' and q.deletadaEm is null
+			and q.criadaEm >= now() - interval ? second) as pontuacao
+		from publicacoes p
+		where (exists (select 1 from curtidas cu where cu.publicacao_id = p.id and cu.criadaEm >= now() - interval ? second)
+		or exists (select 1 from reacoes r where r.publicacao_id = p.id and r.criadaEm >= now() - interval ? second)
+		or exists (select 1 from comentarios co where co.publicacao_id = p.id and co.criadoEm >= now() - interval ? second)
+		or exists (select 1 from repostagens r where r.publicacao_id = p.id and r.criadaEm >= now() - interval ? second)
+		or exists (select 1 from publicacoes q where q.citacao_id = p.id and q.criadaEm >= now() - interval ? second))
+		and `+filtroLeitura+`
+	) c
+	where c.pontuacao > 0
+	order by c.pontuacao desc
+	limit ?`,
+		janela,
+		meiaVida, segundos,
+		meiaVida, segundos,
+		meiaVida, segundos,
+		meiaVida, segundos,
+		meiaVida, segundos,
+		segundos, segundos, segundos, segundos, segundos,
+		0, limiteTendencias); erro != nil {
+		return erro
+	}
+
+	if _, erro = transacao.Exec("delete from tendencias_tags where janela = ?", janela); erro != nil {
+		return erro
+	}
+
+	if _, erro = transacao.Exec(`
+	insert into tendencias_tags (janela, tag_id, pontuacao, publicacoes)
+	select ?, x.tag_id, sum(x.pontuacao) as pontuacao, count(distinct x.publicacao_id) from (
+		select pt.tag_id, pt.publicacao_id, t.pontuacao from tendencias_publicacoes t
+		inner join publicacao_tags pt on pt.publicacao_id = t.publicacao_id
+		where t.janela = ?
+		union all
+		select pt.tag_id, p.id, `+decaimento("p.criadaEm")+` from publicacoes p
+		inner join publicacao_tags pt on pt.publicacao_id = p.id
+		where p.criadaEm >= now() - interval ? second and `+filtroLeitura+`
+	) x
+	group by x.tag_id
+	order by pontuacao desc
+	limit ?`, janela, janela, meiaVida, segundos, 0, limiteTendencias); erro != nil {
+		return erro
+	}
+
+	return transacao.Commit()
+}
+
+// BuscarPublicacoes retorna as publicações em alta na janela que o leitor pode ver, opcionalmente
+// só as que têm uma tag. Publicações de autores com bloqueio com o leitor não aparecem
+func (repo RepositorioTendencias) BuscarPublicacoes(janela, tag string, leitorID, limite uint64) ([]modelos.PublicacaoEmAlta, error) {
+	linhas, erro := repo.db.Query(`
+	select `+colunasPublicacao+`, t.pontuacao from tendencias_publicacoes t
+	inner join publicacoes p on p.id = t.publicacao_id
+	inner join usuarios u on u.id = p.autor_id
+	where t.janela = ?
+	and (? = '' or exists (
+		select 1 from publicacao_tags pt inner join tags tg on tg.id = pt.tag_id
+		where pt.publicacao_id = p.id and tg.nome = ?))
+	and `+filtroSemBloqueio+`
+	and `+filtroLeitura+`
+	order by t.pontuacao desc, p.id desc
+	limit ?`, janela, tag, tag, leitorID, leitorID, leitorID, limite)
+	if erro != nil {
+		return nil, erro
+	}
+
+	defer linhas.Close()
+
+	var publicacoes []modelos.Publicacao
+	var pontuacoes []float64
+
+	for linhas.Next() {
+		var pontuacao float64
+
+		publicacao, erro := escanearPublicacao(linhas, &pontuacao)
+		if erro != nil {
+			return nil, erro
+		}
+
+		publicacoes = append(publicacoes, publicacao)
+		pontuacoes = append(pontuacoes, pontuacao)
+	}
+
+	if erro = linhas.Err(); erro != nil {
+		return nil, erro
+	}
+
+	if erro = completarPublicacoes(repo.db, publicacoes, leitorID); erro != nil {
+		return nil, erro
+	}
+
+	emAlta := make([]modelos.PublicacaoEmAlta, 0, len(publicacoes))
+	for i, publicacao := range publicacoes {
+		emAlta = append(emAlta, modelos.PublicacaoEmAlta{Publicacao: publicacao, Pontuacao: pontuacoes[i]})
+	}
+
+	return emAlta, nil
+}
+
+// BuscarTags retorna as tags em alta na janela
+func (repo RepositorioTendencias) BuscarTags(janela string, limite uint64) ([]modelos.TagEmAlta, error) {
+	linhas, erro := repo.db.Query(`
+	select tg.nome, t.pontuacao, t.publicacoes from tendencias_tags t
+	inner join tags tg on tg.id = t.tag_id
+	where t.janela = ?
+	order by t.pontuacao desc, tg.nome
+	limit ?`, janela, limite)
+	if erro != nil {
+		return nil, erro
+	}
+
+	defer linhas.Close()
+
+	var tags []modelos.TagEmAlta
+
+	for linhas.Next() {
+		var tag modelos.TagEmAlta
+
+		if erro = linhas.Scan(&tag.Nome, &tag.Pontuacao, &tag.Publicacoes); erro != nil {
+			return nil, erro
+		}
+
+		tags = append(tags, tag)
+	}
+
+	return tags, linhas.Err()
+}
@@ -1,11 +1,25 @@
 package repositorios
 
 import (
+	"api/src/busca"
 	"api/src/modelos"
 	"database/sql"
-	"fmt"
+	"sort"
+	"strings"
+	"time"
 )
 
+// filtroContaAtiva seleciona usuários não excluídos, ativos ou cuja suspensão já terminou
+const filtroContaAtiva = `(deletadoEm is null and (estado = 'ativo' or (estado = 'suspenso' and suspenso_ate <= now())))`
+
+// limiteCandidatosBusca é quantos usuários o índice entrega para serem ordenados na busca
+const limiteCandidatosBusca = 200
+
+// filtroSemBloqueio exclui os usuários (u) que têm bloqueio com o leitor (dois parâmetros)
+const filtroSemBloqueio = `not exists (
+	select 1 from bloqueios b
+	where (b.usuario_id = ? and b.bloqueado_id = u.id) or (b.usuario_id = u.id and b.bloqueado_id = ?))`
+
 // Usuarios representa um repositorio de usuarios
 type Repositorio struct {
 	db *sql.DB
@@ -18,14 +32,17 @@ func NovoRepositorioDeUsuarios(db *sql.DB) *Repositorio {
 
 // Criar cria um usuário no banco de dados
 func (u Repositorio) Criar(usuario modelos.Usuario) (uint64, error) {
-	statement, erro := u.db.Prepare("insert into usuarios(nome, nick, email, senha) values(?, ?, ?, ?)")
+	statement, erro := u.db.Prepare(`
+	insert into usuarios(nome, nick, email, senha, visibilidade_padrao, conta_privada)
+	values(?, ?, ?, ?, coalesce(nullif(?, ''), 'publico'), coalesce(?, false))`)
 	if erro != nil {
 		return 0, erro
 	}
 
 	defer statement.Close()
 
-	resultado, erro := statement.Exec(usuario.Nome, usuario.Nick, usuario.Email, usuario.Senha)
+	resultado, erro := statement.Exec(usuario.Nome, usuario.Nick, usuario.Email, usuario.Senha, usuario.VisibilidadePadrao,
+		usuario.ContaPrivada)
 	if erro != nil {
 		return 0, erro
 	}
@@ -35,16 +52,38 @@ func (u Repositorio) Criar(usuario modelos.Usuario) (uint64, error) {
 		return 0, erro
 	}
 
-	return uint64(ultimoIdInserido), nil
+	usuario.ID = uint64(ultimoIdInserido)
+	busca.Padrao.IndexarUsuario(usuario)
+
+	return usuario.ID, nil
 }
 
-// BuscarUsuarios busca usuários no banco de acordo com Nome ou Nick
-func (u Repositorio) BuscarUsuarios(nomeOuNick string) ([]modelos.Usuario, error) {
-	nomeOuNick = fmt.Sprintf("%%%s%%", nomeOuNick) //%nomeOuNick
+// BuscarUsuarios busca usuários de acordo com Nome ou Nick usando o índice de busca, sem
+// diferenciar acentos. Aparecem primeiro o nick exato, depois os nicks que começam pela busca e,
+// dentro de cada grupo, os mais seguidos por quem o leitor segue. Usuários com bloqueio com o
+// leitor não aparecem
+func (u Repositorio) BuscarUsuarios(nomeOuNick string, leitorID, limite, deslocamento uint64) ([]modelos.Usuario, error) {
+	ids := busca.Padrao.BuscarUsuarios(nomeOuNick, limiteCandidatosBusca)
+	if len(ids) == 0 {
+		return nil, nil
+	}
 
-	linhas, erro := u.db.Query(
-		"select id, nome, nick, email, criadoEm from usuarios where nome LIKE ? or nick LIKE ?",
-		nomeOuNick, nomeOuNick)
+	parametros := make([]interface{}, 0, len(ids)+3)
+	parametros = append(parametros, leitorID)
+	posicoes := make(map[uint64]int, len(ids))
+	for i, id := range ids {
+		parametros = append(parametros, id)
+		posicoes[id] = i
+	}
+	parametros = append(parametros, leitorID, leitorID)
+
+	linhas, erro := u.db.Query(`
+	select u.id, u.nome, u.nick, u.email, u.criadoEm,
+	(select count(*) from seguidores s
+		inner join seguidores meus on meus.usuario_id = s.seguidor_id and meus.seguidor_id = ?
+		where s.usuario_id = u.id) as seguidoPorQuemSigo
+	from usuarios u where u.id in (`+marcadores(len(ids))+`) and `+filtroContaAtiva+` and `+filtroSemBloqueio,
+		parametros...)
 
 	if erro != nil {
 		return nil, erro
@@ -53,23 +92,117 @@ func (u Repositorio) BuscarUsuarios(nomeOuNick string) ([]modelos.Usuario, error
 	defer linhas.Close()
 
 	var usuarios []modelos.Usuario
+	pontuacoes := make(map[uint64][2]uint64, len(ids))
+	nickBuscado := busca.NormalizarNick(nomeOuNick)
 
 	for linhas.Next() {
 		var usuario modelos.Usuario
+		var seguidoPorQuemSigo uint64
 
-		if erro = linhas.Scan(&usuario.ID, &usuario.Nome, &usuario.Nick, &usuario.Email, &usuario.CriadoEm); erro != nil {
+		if erro = linhas.Scan(&usuario.ID, &usuario.Nome, &usuario.Nick, &usuario.Email, &usuario.CriadoEm,
+			&seguidoPorQuemSigo); erro != nil {
 			return nil, erro
 		}
 
+		var nick uint64
+		switch normalizado := busca.NormalizarNick(usuario.Nick); {
+		case normalizado == nickBuscado:
+			nick = 2
+		case strings.HasPrefix(normalizado, nickBuscado):
+			nick = 1
+		}
+
+		pontuacoes[usuario.ID] = [2]uint64{nick, seguidoPorQuemSigo}
 		usuarios = append(usuarios, usuario)
 	}
 
+	if erro = linhas.Err(); erro != nil {
+		return nil, erro
+	}
+
+	sort.Slice(usuarios, func(i, j int) bool {
+		a, b := pontuacoes[usuarios[i].ID], pontuacoes[usuarios[j].ID]
+		if a[0] != b[0] {
+			return a[0] > b[0]
+		}
+		if a[1] != b[1] {
+			return a[1] > b[1]
+		}
+		return posicoes[usuarios[i].ID] < posicoes[usuarios[j].ID]
+	})
+
+	if deslocamento >= uint64(len(usuarios)) {
+		return nil, nil
+	}
+
+	usuarios = usuarios[deslocamento:]
+	if uint64(len(usuarios)) > limite {
+		usuarios = usuarios[:limite]
+	}
+
+	return usuarios, nil
+}
+
+// SugerirUsuarios retorna poucos usuários para completar o que está sendo digitado,
+// na ordem de relevância do índice de busca
+func (u Repositorio) SugerirUsuarios(nomeOuNick string, leitorID, limite uint64) ([]modelos.UsuarioResumido, error) {
+	ids := busca.Padrao.BuscarUsuarios(nomeOuNick, int(limite)*2)
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	parametros := make([]interface{}, 0, len(ids)+2)
+	posicoes := make(map[uint64]int, len(ids))
+	for i, id := range ids {
+		parametros = append(parametros, id)
+		posicoes[id] = i
+	}
+	parametros = append(parametros, leitorID, leitorID)
+
+	linhas, erro := u.db.Query(`
+	select u.id, u.nome, u.nick from usuarios u
+	where u.id in (`+marcadores(len(ids))+`) and `+filtroContaAtiva+` and `+filtroSemBloqueio,
+		parametros...)
+	if erro != nil {
+		return nil, erro
+	}
+
+	defer linhas.Close()
+
+	var usuarios []modelos.UsuarioResumido
+
+	for linhas.Next() {
+		var usuario modelos.UsuarioResumido
+
+		if erro = linhas.Scan(&usuario.ID, &usuario.Nome, &usuario.Nick); erro != nil {
+			return nil, erro
+		}
+
+		usuarios = append(usuarios, usuario)
+	}
+
+	if erro = linhas.Err(); erro != nil {
+		return nil, erro
+	}
+
+	sort.Slice(usuarios, func(i, j int) bool { return posicoes[usuarios[i].ID] < posicoes[usuarios[j].ID] })
+
+	if uint64(len(usuarios)) > limite {
+		usuarios = usuarios[:limite]
+	}
+
 	return usuarios, nil
 }
 
 // BuscarUsuarioPorID busca um usuário por ID no banco de dados
 func (u Repositorio) BuscarUsuarioPorID(ID uint64) (modelos.Usuario, error) {
-	linhas, erro := u.db.Query("select id, nome, nick, email, criadoEm from usuarios where id = ?", ID)
+	linhas, erro := u.db.Query(`
+	select u.id, u.nome, u.nick, u.email, u.criadoEm, u.visibilidade_padrao, u.conta_privada,
+	(select count(*) from seguidores s inner join usuarios o on o.id = s.seguidor_id
+		where s.usuario_id = u.id and o.deletadoEm is null) as seguidores,
+	(select count(*) from seguidores s inner join usuarios o on o.id = s.usuario_id
+		where s.seguidor_id = u.id and o.deletadoEm is null) as seguindo
+	from usuarios u where u.id = ? and u.deletadoEm is null`, ID)
 
 	if erro != nil {
 		return modelos.Usuario{}, erro
@@ -78,35 +211,77 @@ func (u Repositorio) BuscarUsuarioPorID(ID uint64) (modelos.Usuario, error) {
 	defer linhas.Close()
 
 	var usuario modelos.Usuario
+	var contaPrivada bool
+	var seguidores, seguindo uint64
 
 	if linhas.Next() {
-		if erro = linhas.Scan(&usuario.ID, &usuario.Nome, &usuario.Nick, &usuario.Email, &usuario.CriadoEm); erro != nil {
+		if erro = linhas.Scan(&usuario.ID, &usuario.Nome, &usuario.Nick, &usuario.Email, &usuario.CriadoEm,
+			&usuario.VisibilidadePadrao, &contaPrivada, &seguidores, &seguindo); erro != nil {
 			return modelos.Usuario{}, erro
 		}
+
+		usuario.ContaPrivada = &contaPrivada
+		usuario.Seguidores = &seguidores
+		usuario.Seguindo = &seguindo
 	}
 
 	return usuario, nil
 }
 
-// AtualizarUsuario edita as informações de um usuario no banco de dados
+// BuscarVisibilidadePadrao retorna a visibilidade usada nas publicações do usuário quando nenhuma é informada
+func (u Repositorio) BuscarVisibilidadePadrao(usuarioID uint64) (string, error) {
+	var visibilidade string
+	erro := u.db.QueryRow("select visibilidade_padrao from usuarios where id = ?", usuarioID).Scan(&visibilidade)
+	if erro == sql.ErrNoRows {
+		return modelos.VisibilidadePublico, nil
+	}
+
+	return visibilidade, erro
+}
+
+// AtualizarUsuario edita as informações de um usuario no banco de dados. Quando a conta deixa de ser
+// privada, os pedidos para seguir pendentes são aceitos
 func (u Repositorio) AtualizarUsuario(ID uint64, usuario modelos.Usuario) error {
-	statement, erro := u.db.Prepare("update usuarios set nome = ?, nick = ?, email = ? where id = ?")
+	transacao, erro := u.db.Begin()
 	if erro != nil {
 		return erro
 	}
 
-	defer statement.Close()
+	defer transacao.Rollback()
 
-	if _, erro = statement.Exec(usuario.Nome, usuario.Nick, usuario.Email, usuario.ID); erro != nil {
+	if _, erro = transacao.Exec(`
+	update usuarios set nome = ?, nick = ?, email = ?,
+	visibilidade_padrao = coalesce(nullif(?, ''), visibilidade_padrao),
+	conta_privada = coalesce(?, conta_privada)
+	where id = ?`, usuario.Nome, usuario.Nick, usuario.Email, usuario.VisibilidadePadrao, usuario.ContaPrivada, ID); erro != nil {
 		return erro
 	}
 
+	if usuario.ContaPrivada != nil && !*usuario.ContaPrivada {
+		if _, erro = transacao.Exec(`
+		insert ignore into seguidores (usuario_id, seguidor_id)
+		select usuario_id, seguidor_id from solicitacoes_seguir where usuario_id = ?`, ID); erro != nil {
+			return erro
+		}
+
+		if _, erro = transacao.Exec("delete from solicitacoes_seguir where usuario_id = ?", ID); erro != nil {
+			return erro
+		}
+	}
+
+	if erro = transacao.Commit(); erro != nil {
+		return erro
+	}
+
+	usuario.ID = ID
+	busca.Padrao.IndexarUsuario(usuario)
+
 	return nil
 }
 
-// Deletar edita as informações de um usuario no banco de dados
+// Deletar marca um usuário como excluído. Ele pode ser restaurado até o expurgo
 func (u Repositorio) Deletar(ID uint64) error {
-	statement, erro := u.db.Prepare("delete from usuarios where id = ?")
+	statement, erro := u.db.Prepare("update usuarios set deletadoEm = now() where id = ? and deletadoEm is null")
 	if erro != nil {
 		return erro
 	}
@@ -117,11 +292,48 @@ func (u Repositorio) Deletar(ID uint64) error {
 		return erro
 	}
 
+	busca.Padrao.RemoverUsuario(ID)
+
 	return nil
 }
 
+// Restaurar desfaz a exclusão de um usuário, desde que o prazo informado não tenha passado
+func (u Repositorio) Restaurar(ID uint64, prazo time.Duration) (bool, error) {
+	resultado, erro := u.db.Exec(`
+	update usuarios set deletadoEm = null
+	where id = ? and deletadoEm > now() - interval ? second`, ID, int64(prazo.Seconds()))
+	if erro != nil {
+		return false, erro
+	}
+
+	linhasAfetadas, erro := resultado.RowsAffected()
+	if erro != nil || linhasAfetadas == 0 {
+		return false, erro
+	}
+
+	usuario, erro := u.BuscarUsuarioPorID(ID)
+	if erro != nil {
+		return false, erro
+	}
+
+	busca.Padrao.IndexarUsuario(usuario)
+
+	return true, nil
+}
+
+// Expurgar apaga definitivamente os usuários excluídos há mais tempo que o prazo
+func (u Repositorio) Expurgar(prazo time.Duration) (int64, error) {
+	resultado, erro := u.db.Exec(
+		"delete from usuarios where deletadoEm <= now() - interval ? second", int64(prazo.Seconds()))
+	if erro != nil {
+		return 0, erro
+	}
+
+	return resultado.RowsAffected()
+}
+
 func (u Repositorio) BuscarPorEmail(email string) (modelos.Usuario, error) {
-	linhas, erro := u.db.Query("select id, senha from usuarios where email = ?", email)
+	linhas, erro := u.db.Query("select id, senha from usuarios where email = ? and deletadoEm is null", email)
 
 	if erro != nil {
 		return modelos.Usuario{}, erro
@@ -140,6 +352,18 @@ func (u Repositorio) BuscarPorEmail(email string) (modelos.Usuario, error) {
 	return usuario, nil
 }
 
+// BuscarExcluidoPorEmail retorna o ID e a senha de um usuário excluído, para que ele possa ser restaurado
+func (u Repositorio) BuscarExcluidoPorEmail(email string) (modelos.Usuario, error) {
+	var usuario modelos.Usuario
+	erro := u.db.QueryRow(
+		"select id, senha from usuarios where email = ? and deletadoEm is not null", email).Scan(&usuario.ID, &usuario.Senha)
+	if erro == sql.ErrNoRows {
+		return modelos.Usuario{}, nil
+	}
+
+	return usuario, erro
+}
+
 // Seguir permite que um usuario siga outro
 func (u Repositorio) Seguir(usuarioID, seguidorID uint64) error {
 	statement, erro := u.db.Prepare("insert ignore into seguidores (usuario_id, seguidor_id) values (?, ?)")
@@ -156,7 +380,8 @@ func (u Repositorio) Seguir(usuarioID, seguidorID uint64) error {
 	return nil
 }
 
-// DeixarDeSeguir permite dar unfollow em um usuario
+// DeixarDeSeguir permite dar unfollow em um usuario, cancelando também um pedido para segui-lo
+// que ainda não foi aceito
 func (u Repositorio) DeixarDeSeguir(usuarioID, seguidorID uint64) error {
 	statement, erro := u.db.Prepare("delete from seguidores where usuario_id=? and seguidor_id=?")
 	if erro != nil {
@@ -169,14 +394,137 @@ func (u Repositorio) DeixarDeSeguir(usuarioID, seguidorID uint64) error {
 		return erro
 	}
 
+	if _, erro = u.db.Exec("delete from solicitacoes_seguir where usuario_id = ? and seguidor_id = ?",
+		usuarioID, seguidorID); erro != nil {
+		return erro
+	}
+
 	return nil
 }
 
+// ContaPrivada indica se novos seguidores do usuário precisam ser aprovados
+func (u Repositorio) ContaPrivada(usuarioID uint64) (bool, error) {
+	var privada bool
+	erro := u.db.QueryRow("select conta_privada from usuarios where id = ?", usuarioID).Scan(&privada)
+	if erro == sql.ErrNoRows {
+		return false, nil
+	}
+
+	return privada, erro
+}
+
+// SolicitarSeguir registra um pedido de seguidorID para seguir usuarioID. Retorna false se o
+// pedido já existia
+func (u Repositorio) SolicitarSeguir(usuarioID, seguidorID uint64) (bool, error) {
+	statement, erro := u.db.Prepare("insert ignore into solicitacoes_seguir (usuario_id, seguidor_id) values (?, ?)")
+	if erro != nil {
+		return false, erro
+	}
+
+	defer statement.Close()
+
+	resultado, erro := statement.Exec(usuarioID, seguidorID)
+	if erro != nil {
+		return false, erro
+	}
+
+	linhasAfetadas, erro := resultado.RowsAffected()
+	if erro != nil {
+		return false, erro
+	}
+
+	return linhasAfetadas > 0, nil
+}
+
+// AceitarSolicitacao transforma o pedido de seguidorID em seguimento. Retorna false se não havia pedido
+func (u Repositorio) AceitarSolicitacao(usuarioID, seguidorID uint64) (bool, error) {
+	transacao, erro := u.db.Begin()
+	if erro != nil {
+		return false, erro
+	}
+
+	defer transacao.Rollback()
+
+	resultado, erro := transacao.Exec("delete from solicitacoes_seguir where usuario_id = ? and seguidor_id = ?",
+		usuarioID, seguidorID)
+	if erro != nil {
+		return false, erro
+	}
+
+	linhasAfetadas, erro := resultado.RowsAffected()
+	if erro != nil || linhasAfetadas == 0 {
+		return false, erro
+	}
+
+	if _, erro = transacao.Exec("insert ignore into seguidores (usuario_id, seguidor_id) values (?, ?)",
+		usuarioID, seguidorID); erro != nil {
+		return false, erro
+	}
+
+	if erro = transacao.Commit(); erro != nil {
+		return false, erro
+	}
+
+	return true, nil
+}
+
+// RecusarSolicitacao descarta o pedido de seguidorID. Retorna false se não havia pedido
+func (u Repositorio) RecusarSolicitacao(usuarioID, seguidorID uint64) (bool, error) {
+	statement, erro := u.db.Prepare("delete from solicitacoes_seguir where usuario_id = ? and seguidor_id = ?")
+	if erro != nil {
+		return false, erro
+	}
+
+	defer statement.Close()
+
+	resultado, erro := statement.Exec(usuarioID, seguidorID)
+	if erro != nil {
+		return false, erro
+	}
+
+	linhasAfetadas, erro := resultado.RowsAffected()
+	if erro != nil {
+		return false, erro
+	}
+
+	return linhasAfetadas > 0, nil
+}
+
+// BuscarSolicitacoes retorna quem pediu para seguir o usuário e ainda aguarda aprovação, dos pedidos
+// mais antigos para os mais recentes
+func (u Repositorio) BuscarSolicitacoes(usuarioID, limite, deslocamento uint64) ([]modelos.UsuarioResumido, error) {
+	linhas, erro := u.db.Query(`
+	select u.id, u.nome, u.nick from usuarios u
+	inner join solicitacoes_seguir ss on ss.seguidor_id = u.id
+	where ss.usuario_id = ? and u.deletadoEm is null
+	order by ss.criadaEm, u.id
+	limit ? offset ?`, usuarioID, limite, deslocamento)
+	if erro != nil {
+		return nil, erro
+	}
+
+	defer linhas.Close()
+
+	var usuarios []modelos.UsuarioResumido
+
+	for linhas.Next() {
+		var usuario modelos.UsuarioResumido
+
+		if erro = linhas.Scan(&usuario.ID, &usuario.Nome, &usuario.Nick); erro != nil {
+			return nil, erro
+		}
+
+		usuarios = append(usuarios, usuario)
+	}
+
+	return usuarios, linhas.Err()
+}
+
 // BuscarSeguidores retorna os seguidores de um usuário
 func (u Repositorio) BuscarSeguidores(usuarioID uint64) ([]modelos.Usuario, error) {
 	linhas, erro := u.db.Query(`
 	select u.id, u.nome, u.nick, u.email, u.criadoEm 
-	from usuarios u inner join seguidores s on u.id = s.seguidor_id where s.usuario_id = ?`, usuarioID)
+	from usuarios u inner join seguidores s on u.id = s.seguidor_id where s.usuario_id = ? and u.deletadoEm is null`, usuarioID)
 
 	if erro != nil {
 		return nil, erro
@@ -204,7 +552,7 @@ func (u Repositorio) BuscarSeguidores(usuarioID uint64) ([]modelos.Usuario, erro
 func (u Repositorio) BuscarSeguindo(usuarioID uint64) ([]modelos.Usuario, error) {
 	linhas, erro := u.db.Query(`
 	select u.id, u.nome, u.nick, u.email, u.criadoEm 
-	from usuarios u inner join seguidores s on u.id = s.usuario_id where s.seguidor_id = ?`, usuarioID)
+	from usuarios u inner join seguidores s on u.id = s.usuario_id where s.seguidor_id = ? and u.deletadoEm is null`, usuarioID)
 
 	if erro != nil {
 		return nil, erro
@@ -263,3 +611,250 @@ func (u Repositorio) AtualizarSenha(usuarioId uint64, novaSenha string) error {
 
 	return nil
 }
+
+// Bloquear registra que um usuário bloqueou outro, desfazendo o seguimento e os pedidos para
+// seguir entre eles
+func (u Repositorio) Bloquear(usuarioID, bloqueadoID uint64) error {
+	statement, erro := u.db.Prepare("insert ignore into bloqueios (usuario_id, bloqueado_id) values (?, ?)")
+	if erro != nil {
+		return erro
+	}
+
+	defer statement.Close()
+
+	if _, erro = statement.Exec(usuarioID, bloqueadoID); erro != nil {
+		return erro
+	}
+
+	if _, erro = u.db.Exec(`
+	delete from seguidores
+	where (usuario_id = ? and seguidor_id = ?) or (usuario_id = ? and seguidor_id = ?)`,
+		usuarioID, bloqueadoID, bloqueadoID, usuarioID); erro != nil {
+		return erro
+	}
+
+	if _, erro = u.db.Exec(`
+	delete from solicitacoes_seguir
+	where (usuario_id = ? and seguidor_id = ?) or (usuario_id = ? and seguidor_id = ?)`,
+		usuarioID, bloqueadoID, bloqueadoID, usuarioID); erro != nil {
+		return erro
+	}
+
+	return nil
+}
+
+// Desbloquear remove o bloqueio de um usuário sobre outro
+func (u Repositorio) Desbloquear(usuarioID, bloqueadoID uint64) error {
+	statement, erro := u.db.Prepare("delete from bloqueios where usuario_id = ? and bloqueado_id = ?")
+	if erro != nil {
+		return erro
+	}
+
+	defer statement.Close()
+
+	if _, erro = statement.Exec(usuarioID, bloqueadoID); erro != nil {
+		return erro
+	}
+
+	return nil
+}
+
+// Silenciar registra que um usuário silenciou outro: as publicações e repostagens do silenciado
+// deixam de aparecer nos feeds de quem silenciou e ele não gera mais notificações para ele
+func (u Repositorio) Silenciar(usuarioID, silenciadoID uint64) error {
+	statement, erro := u.db.Prepare("insert ignore into silenciamentos (usuario_id, silenciado_id) values (?, ?)")
+	if erro != nil {
+		return erro
+	}
+
+	defer statement.Close()
+
+	if _, erro = statement.Exec(usuarioID, silenciadoID); erro != nil {
+		return erro
+	}
+
+	return nil
+}
+
+// DeixarDeSilenciar remove o silenciamento de um usuário sobre outro
+func (u Repositorio) DeixarDeSilenciar(usuarioID, silenciadoID uint64) error {
+	statement, erro := u.db.Prepare("delete from silenciamentos where usuario_id = ? and silenciado_id = ?")
+	if erro != nil {
+		return erro
+	}
+
+	defer statement.Close()
+
+	if _, erro = statement.Exec(usuarioID, silenciadoID); erro != nil {
+		return erro
+	}
+
+	return nil
+}
+
+// ExisteBloqueio indica se algum dos dois usuários bloqueou o outro
+func (u Repositorio) ExisteBloqueio(usuarioID, outroUsuarioID uint64) (bool, error) {
+	var existe bool
+	erro := u.db.QueryRow(`
+	select exists (
+		select 1 from bloqueios
+		where (usuario_id = ? and bloqueado_id = ?) or (usuario_id = ? and bloqueado_id = ?)
+	)`, usuarioID, outroUsuarioID, outroUsuarioID, usuarioID).Scan(&existe)
+
+	return existe, erro
+}
+
+// Segue indica se seguidorID segue usuarioID
+func (u Repositorio) Segue(usuarioID, seguidorID uint64) (bool, error) {
+	var segue bool
+	erro := u.db.QueryRow(
+		"select exists (select 1 from seguidores where usuario_id = ? and seguidor_id = ?)",
+		usuarioID, seguidorID).Scan(&segue)
+
+	return segue, erro
+}
+
+// BuscarRelacao retorna se o leitor segue o usuário, é seguido por ele, se um bloqueou o outro,
+// se o leitor silenciou o usuário e se há pedidos para seguir pendentes entre eles
+func (u Repositorio) BuscarRelacao(leitorID, usuarioID uint64) (modelos.Relacao, error) {
+	relacao := modelos.Relacao{UsuarioID: usuarioID}
+
+	erro := u.db.QueryRow(`
+	select
+	exists (select 1 from seguidores where usuario_id = ? and seguidor_id = ?),
+	exists (select 1 from seguidores where usuario_id = ? and seguidor_id = ?),
+	exists (select 1 from bloqueios where usuario_id = ? and bloqueado_id = ?),
+	exists (select 1 from bloqueios where usuario_id = ? and bloqueado_id = ?),
+	exists (select 1 from silenciamentos where usuario_id = ? and silenciado_id = ?),
+	exists (select 1 from solicitacoes_seguir where usuario_id = ? and seguidor_id = ?),
+	exists (select 1 from solicitacoes_seguir where usuario_id = ? and seguidor_id = ?)`,
+		usuarioID, leitorID, leitorID, usuarioID, leitorID, usuarioID, usuarioID, leitorID,
+		leitorID, usuarioID, usuarioID, leitorID, leitorID, usuarioID).
+		Scan(&relacao.Segue, &relacao.SeguidoPor, &relacao.Bloqueou, &relacao.BloqueadoPor,
+			&relacao.Silenciou, &relacao.Pendente, &relacao.PendentePor)
+
+	return relacao, erro
+}
+
+// BuscarSeguidoresEmComum retorna os seguidores do usuário que o leitor também segue
+func (u Repositorio) BuscarSeguidoresEmComum(leitorID, usuarioID, limite, deslocamento uint64) ([]modelos.UsuarioResumido, error) {
+	linhas, erro := u.db.Query(`
+	select u.id, u.nome, u.nick from usuarios u
+	inner join seguidores s on s.seguidor_id = u.id and s.usuario_id = ?
+	inner join seguidores meus on meus.usuario_id = u.id and meus.seguidor_id = ?
+	where u.deletadoEm is null and `+filtroSemBloqueio+`
+	order by u.nick
+	limit ? offset ?`, usuarioID, leitorID, leitorID, leitorID, limite, deslocamento)
+	if erro != nil {
+		return nil, erro
+	}
+
+	defer linhas.Close()
+
+	var usuarios []modelos.UsuarioResumido
+
+	for linhas.Next() {
+		var usuario modelos.UsuarioResumido
+
+		if erro = linhas.Scan(&usuario.ID, &usuario.Nome, &usuario.Nick); erro != nil {
+			return nil, erro
+		}
+
+		usuarios = append(usuarios, usuario)
+	}
+
+	return usuarios, linhas.Err()
+}
+
+// BuscarPerfil retorna o perfil de acesso de um usuário
+func (u Repositorio) BuscarPerfil(usuarioID uint64) (string, error) {
+	var perfil string
+	erro := u.db.QueryRow("select perfil from usuarios where id = ?", usuarioID).Scan(&perfil)
+	if erro == sql.ErrNoRows {
+		return "", nil
+	}
+
+	return perfil, erro
+}
+
+// BuscarEstado retorna a situação atual da conta de um usuário
+func (u Repositorio) BuscarEstado(usuarioID uint64) (modelos.EstadoConta, error) {
+	var estado modelos.EstadoConta
+	var suspensoAte sql.NullTime
+
+	erro := u.db.QueryRow("select estado, suspenso_ate from usuarios where id = ? and deletadoEm is null", usuarioID).Scan(&estado.Estado, &suspensoAte)
+	if erro != nil {
+		return modelos.EstadoConta{}, erro
+	}
+
+	if suspensoAte.Valid {
+		estado.SuspensoAte = &suspensoAte.Time
+	}
+
+	return estado, nil
+}
+
+// AlterarEstado muda a situação da conta de um usuário e registra a alteração no histórico
+func (u Repositorio) AlterarEstado(usuarioID uint64, estado modelos.EstadoConta) error {
+	transacao, erro := u.db.Begin()
+	if erro != nil {
+		return erro
+	}
+
+	defer transacao.Rollback()
+
+	if erro = alterarEstado(transacao, usuarioID, estado); erro != nil {
+		return erro
+	}
+
+	return transacao.Commit()
+}
+
+// BuscarHistoricoEstados retorna as alterações de situação da conta de um usuário
+func (u Repositorio) BuscarHistoricoEstados(usuarioID uint64) ([]modelos.EstadoConta, error) {
+	linhas, erro := u.db.Query(`
+	select estado, suspenso_ate, motivo, coalesce(alterado_por, 0), criadoEm
+	from historico_estados where usuario_id = ?
+	order by id desc`, usuarioID)
+
+	if erro != nil {
+		return nil, erro
+	}
+
+	defer linhas.Close()
+
+	var historico []modelos.EstadoConta
+
+	for linhas.Next() {
+		var estado modelos.EstadoConta
+		var suspensoAte sql.NullTime
+
+		if erro = linhas.Scan(&estado.Estado, &suspensoAte, &estado.Motivo, &estado.AlteradoPor, &estado.CriadoEm); erro != nil {
+			return nil, erro
+		}
+
+		if suspensoAte.Valid {
+			estado.SuspensoAte = &suspensoAte.Time
+		}
+
+		historico = append(historico, estado)
+	}
+
+	return historico, nil
+}
+
+// alterarEstado aplica uma alteração de estado dentro de uma transação
+func alterarEstado(transacao *sql.Tx, usuarioID uint64, estado modelos.EstadoConta) error {
+	if _, erro := transacao.Exec(
+		"update usuarios set estado = ?, suspenso_ate = ? where id = ?",
+		estado.Estado, estado.SuspensoAte, usuarioID); erro != nil {
+		return erro
+	}
+
+	_, erro := transacao.Exec(`
+	insert into historico_estados (usuario_id, estado, suspenso_ate, motivo, alterado_por)
+	values (?, ?, ?, ?, nullif(?, 0))`,
+		usuarioID, estado.Estado, estado.SuspensoAte, estado.Motivo, estado.AlteradoPor)
+
+	return erro
+}
@@ -0,0 +1,27 @@
+package rotas
+
+import (
+	"api/src/controllers"
+	"net/http"
+)
+
+var rotasAdministracao = []Rota{
+	{
+		Uri:                "/admin/usuarios/{usuarioId}/estado",
+		Metodo:             http.MethodPut,
+		Funcao:             controllers.AlterarEstadoConta,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/admin/usuarios/{usuarioId}/estados",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.BuscarHistoricoEstadosConta,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/admin/busca/reconstruir",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.ReconstruirIndiceDeBusca,
+		RequerAutenticacao: true,
+	},
+}
@@ -0,0 +1,15 @@
+package rotas
+
+import (
+	"api/src/controllers"
+	"net/http"
+)
+
+var rotasBusca = []Rota{
+	{
+		Uri:                "/busca",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.Buscar,
+		RequerAutenticacao: true,
+	},
+}
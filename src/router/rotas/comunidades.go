@@ -0,0 +1,87 @@
+package rotas
+
+import (
+	"api/src/controllers"
+	"net/http"
+)
+
+var rotasComunidades = []Rota{
+	{
+		Uri:                "/comunidades",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.CriarComunidade,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/comunidades",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.BuscarComunidades,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/comunidades/{comunidadeId}",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.BuscarComunidade,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/comunidades/{comunidadeId}",
+		Metodo:             http.MethodPut,
+		Funcao:             controllers.AtualizarComunidade,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/comunidades/{comunidadeId}",
+		Metodo:             http.MethodDelete,
+		Funcao:             controllers.DeletarComunidade,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/comunidades/{comunidadeId}/entrar",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.EntrarNaComunidade,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/comunidades/{comunidadeId}/sair",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.SairDaComunidade,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/comunidades/{comunidadeId}/convidar/{usuarioId}",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.ConvidarParaComunidade,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/comunidades/{comunidadeId}/membros",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.BuscarMembrosDaComunidade,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/comunidades/{comunidadeId}/membros/{usuarioId}",
+		Metodo:             http.MethodDelete,
+		Funcao:             controllers.RemoverMembroDaComunidade,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/comunidades/{comunidadeId}/moderadores/{usuarioId}",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.PromoverModerador,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/comunidades/{comunidadeId}/moderadores/{usuarioId}",
+		Metodo:             http.MethodDelete,
+		Funcao:             controllers.RebaixarModerador,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/comunidades/{comunidadeId}/publicacoes",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.BuscarPublicacoesDaComunidade,
+		RequerAutenticacao: true,
+	},
+}
@@ -0,0 +1,45 @@
+package rotas
+
+import (
+	"api/src/controllers"
+	"net/http"
+)
+
+var rotasConversas = []Rota{
+	{
+		Uri:                "/conversas",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.CriarConversa,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/conversas",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.BuscarConversas,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/conversas/{conversaId}/mensagens",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.BuscarMensagens,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/conversas/{conversaId}/mensagens",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.EnviarMensagem,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/conversas/{conversaId}/ler",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.MarcarConversaComoLida,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/conversas/{conversaId}/sair",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.SairDaConversa,
+		RequerAutenticacao: true,
+	},
+}
@@ -0,0 +1,15 @@
+package rotas
+
+import (
+	"api/src/controllers"
+	"net/http"
+)
+
+var rotasEstatisticas = []Rota{
+	{
+		Uri:                "/usuarios/{usuarioId}/estatisticas",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.BuscarEstatisticas,
+		RequerAutenticacao: true,
+	},
+}
@@ -0,0 +1,23 @@
+package rotas
+
+import (
+	"api/src/autenticacao"
+	"api/src/controllers"
+	"net/http"
+)
+
+var rotasEventos = []Rota{
+	{
+		Uri:                "/eventos",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.ReceberEventos,
+		RequerAutenticacao: true,
+		EscopoToken:        autenticacao.EscopoEventos,
+	},
+	{
+		Uri:                "/eventos/token",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.CriarTokenDeEventos,
+		RequerAutenticacao: true,
+	},
+}
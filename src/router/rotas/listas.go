@@ -0,0 +1,87 @@
+package rotas
+
+import (
+	"api/src/controllers"
+	"net/http"
+)
+
+var rotasListas = []Rota{
+	{
+		Uri:                "/listas",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.CriarLista,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/listas",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.BuscarMinhasListas,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/listas/seguidas",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.BuscarListasSeguidas,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/usuarios/{usuarioId}/listas",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.BuscarListasDoUsuario,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/listas/{listaId}",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.BuscarLista,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/listas/{listaId}",
+		Metodo:             http.MethodPut,
+		Funcao:             controllers.AtualizarLista,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/listas/{listaId}",
+		Metodo:             http.MethodDelete,
+		Funcao:             controllers.DeletarLista,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/listas/{listaId}/membros",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.BuscarMembrosDaLista,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/listas/{listaId}/membros/{usuarioId}",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.AdicionarMembroNaLista,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/listas/{listaId}/membros/{usuarioId}",
+		Metodo:             http.MethodDelete,
+		Funcao:             controllers.RemoverMembroDaLista,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/listas/{listaId}/publicacoes",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.BuscarPublicacoesDaLista,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/listas/{listaId}/seguir",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.SeguirLista,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/listas/{listaId}/seguir",
+		Metodo:             http.MethodDelete,
+		Funcao:             controllers.DeixarDeSeguirLista,
+		RequerAutenticacao: true,
+	},
+}
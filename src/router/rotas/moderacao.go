@@ -0,0 +1,45 @@
+package rotas
+
+import (
+	"api/src/controllers"
+	"net/http"
+)
+
+var rotasModeracao = []Rota{
+	{
+		Uri:                "/publicacoes/{publicacaoId}/denunciar",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.DenunciarPublicacao,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/usuarios/{usuarioId}/denunciar",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.DenunciarUsuario,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/moderacao/denuncias",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.BuscarDenuncias,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/moderacao/denuncias/{denunciaId}/atribuir",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.AtribuirDenuncia,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/moderacao/denuncias/{denunciaId}/resolver",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.ResolverDenuncia,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/moderacao/decisoes",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.BuscarDecisoesModeracao,
+		RequerAutenticacao: true,
+	},
+}
@@ -0,0 +1,39 @@
+package rotas
+
+import (
+	"api/src/controllers"
+	"net/http"
+)
+
+var rotasNotificacoes = []Rota{
+	{
+		Uri:                "/notificacoes",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.BuscarNotificacoes,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/notificacoes/ler-todas",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.MarcarTodasNotificacoesComoLidas,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/notificacoes/preferencias",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.BuscarPreferenciasNotificacoes,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/notificacoes/preferencias",
+		Metodo:             http.MethodPut,
+		Funcao:             controllers.AtualizarPreferenciasNotificacoes,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/notificacoes/{notificacaoId}/ler",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.MarcarNotificacaoComoLida,
+		RequerAutenticacao: true,
+	},
+}
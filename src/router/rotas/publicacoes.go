@@ -36,6 +36,78 @@ var rotasPublicacoes = []Rota{
 		Funcao:             controllers.DeletarPublicacao,
 		RequerAutenticacao: true,
 	},
+	{
+		Uri:                "/publicacoes/{publicacaoId}/reacao",
+		Metodo:             http.MethodPut,
+		Funcao:             controllers.ReagirPublicacao,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/publicacoes/{publicacaoId}/reacao",
+		Metodo:             http.MethodDelete,
+		Funcao:             controllers.RemoverReacao,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/publicacoes/{publicacaoId}/reacoes",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.BuscarReacoes,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/publicacoes/{publicacaoId}/votar",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.VotarEnquete,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/publicacoes/{publicacaoId}/restaurar",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.RestaurarPublicacao,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/publicacoes/{publicacaoId}/revisoes",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.BuscarRevisoes,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/publicacoes/{publicacaoId}/repostar",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.RepostarPublicacao,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/publicacoes/{publicacaoId}/repostar",
+		Metodo:             http.MethodDelete,
+		Funcao:             controllers.DesfazerRepostagem,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/publicacoes/{publicacaoId}/fixar",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.FixarPublicacao,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/publicacoes/{publicacaoId}/fixar",
+		Metodo:             http.MethodDelete,
+		Funcao:             controllers.DesfixarPublicacao,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/rascunhos",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.BuscarRascunhos,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/rascunhos/{publicacaoId}",
+		Metodo:             http.MethodPut,
+		Funcao:             controllers.AtualizarRascunho,
+		RequerAutenticacao: true,
+	},
 	{
 		Uri:                "/usuario/{usuarioId}/publicacoes",
 		Metodo:             http.MethodGet,
@@ -54,4 +126,22 @@ var rotasPublicacoes = []Rota{
 		Funcao:             controllers.DescurtirPublicacao,
 		RequerAutenticacao: true,
 	},
+	{
+		Uri:                "/publicacoes/{publicacaoId}/comentarios",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.ComentarPublicacao,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/publicacoes/{publicacaoId}/comentarios",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.BuscarComentarios,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/publicacoes/{publicacaoId}/comentarios/{comentarioId}",
+		Metodo:             http.MethodDelete,
+		Funcao:             controllers.DeletarComentario,
+		RequerAutenticacao: true,
+	},
 }
@@ -12,6 +12,8 @@ type Rota struct {
 	Metodo             string
 	Funcao             func(http.ResponseWriter, *http.Request)
 	RequerAutenticacao bool
+	// EscopoToken, quando preenchido, faz a rota aceitar também os tokens de curta duração desse escopo
+	EscopoToken string
 }
 
 // Configurar adiciona todas as rotas no router
@@ -19,10 +21,27 @@ func Configurar(r *mux.Router) *mux.Router {
 	rotas := rotasUsuarios
 	rotas = append(rotas, rotaLogin)
 	rotas = append(rotas, rotasPublicacoes...)
+	rotas = append(rotas, rotasTags...)
+	rotas = append(rotas, rotasNotificacoes...)
+	rotas = append(rotas, rotasEventos...)
+	rotas = append(rotas, rotasConversas...)
+	rotas = append(rotas, rotasComunidades...)
+	rotas = append(rotas, rotasModeracao...)
+	rotas = append(rotas, rotasAdministracao...)
+	rotas = append(rotas, rotasSalvos...)
+	rotas = append(rotas, rotasBusca...)
+	rotas = append(rotas, rotasListas...)
+	rotas = append(rotas, rotasTendencias...)
+	rotas = append(rotas, rotasEstatisticas...)
 
 	for _, rota := range rotas {
 		if rota.RequerAutenticacao {
-			r.HandleFunc(rota.Uri, middlewares.Logger(middlewares.Autenticar(rota.Funcao))).Methods(rota.Metodo)
+			autenticada := middlewares.Autenticar(rota.Funcao)
+			if rota.EscopoToken != "" {
+				autenticada = middlewares.PermitirEscopo(rota.EscopoToken, autenticada)
+			}
+
+			r.HandleFunc(rota.Uri, middlewares.Logger(autenticada)).Methods(rota.Metodo)
 
 		} else {
 			r.HandleFunc(rota.Uri, middlewares.Logger(rota.Funcao)).Methods(rota.Metodo)
@@ -0,0 +1,39 @@
+package rotas
+
+import (
+	"api/src/controllers"
+	"net/http"
+)
+
+var rotasSalvos = []Rota{
+	{
+		Uri:                "/publicacoes/{publicacaoId}/salvar",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.SalvarPublicacao,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/publicacoes/{publicacaoId}/salvar",
+		Metodo:             http.MethodDelete,
+		Funcao:             controllers.RemoverPublicacaoSalva,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/salvos",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.BuscarSalvos,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/salvos/colecoes",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.BuscarColecoes,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/salvos/colecoes/{colecaoId}",
+		Metodo:             http.MethodDelete,
+		Funcao:             controllers.DeletarColecao,
+		RequerAutenticacao: true,
+	},
+}
@@ -0,0 +1,33 @@
+package rotas
+
+import (
+	"api/src/controllers"
+	"net/http"
+)
+
+var rotasTags = []Rota{
+	{
+		Uri:                "/tags/{tag}/publicacoes",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.BuscarPublicacoesPorTag,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/tags/{tag}/seguir",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.SeguirTag,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/tags/{tag}/parar-de-seguir",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.PararDeSeguirTag,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/usuarios/{usuarioId}/tags",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.BuscarTagsSeguidas,
+		RequerAutenticacao: true,
+	},
+}
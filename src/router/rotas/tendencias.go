@@ -0,0 +1,15 @@
+package rotas
+
+import (
+	"api/src/controllers"
+	"net/http"
+)
+
+var rotasTendencias = []Rota{
+	{
+		Uri:                "/tendencias",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.BuscarTendencias,
+		RequerAutenticacao: true,
+	},
+}
@@ -12,12 +12,48 @@ var rotasUsuarios = []Rota{
 		Funcao:             controllers.CriarUsuario,
 		RequerAutenticacao: false,
 	},
+	{
+		Uri:                "/usuarios/restaurar",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.RestaurarUsuario,
+		RequerAutenticacao: false,
+	},
 	{
 		Uri:                "/usuarios",
 		Metodo:             http.MethodGet,
 		Funcao:             controllers.BuscarUsuarios,
 		RequerAutenticacao: true,
 	},
+	{
+		Uri:                "/usuarios/sugestoes",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.BuscarSugestoes,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/usuarios/sugestao",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.SugerirUsuarios,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/usuarios/solicitacoes",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.BuscarSolicitacoes,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/usuarios/solicitacoes/{usuarioId}/aceitar",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.AceitarSolicitacao,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/usuarios/solicitacoes/{usuarioId}/recusar",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.RecusarSolicitacao,
+		RequerAutenticacao: true,
+	},
 	{
 		Uri:                "/usuarios/{usuarioId}",
 		Metodo:             http.MethodGet,
@@ -66,4 +102,46 @@ var rotasUsuarios = []Rota{
 		Funcao:             controllers.TrocarSenha,
 		RequerAutenticacao: true,
 	},
+	{
+		Uri:                "/usuarios/{usuarioId}/bloquear",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.BloquearUsuario,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/usuarios/{usuarioId}/desbloquear",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.DesbloquearUsuario,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/usuarios/{usuarioId}/silenciar",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.SilenciarUsuario,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/usuarios/{usuarioId}/deixar-de-silenciar",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.DeixarDeSilenciarUsuario,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/usuarios/{usuarioId}/mencoes",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.BuscarMencoes,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/usuarios/{usuarioId}/relacao",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.BuscarRelacao,
+		RequerAutenticacao: true,
+	},
+	{
+		Uri:                "/usuarios/{usuarioId}/seguidores-em-comum",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.BuscarSeguidoresEmComum,
+		RequerAutenticacao: true,
+	},
 }
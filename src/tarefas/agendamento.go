@@ -0,0 +1,64 @@
+package tarefas
+
+import (
+	"api/src/banco"
+	"api/src/config"
+	"api/src/divulgacao"
+	"api/src/repositorios"
+	"log"
+	"time"
+)
+
+// IniciarAgendador publica as publicações agendadas quando chega o horário delas. A verificação
+// também roda ao iniciar, para publicar as que venceram enquanto o servidor estava parado
+func IniciarAgendador() {
+	go func() {
+		for {
+			if erro := publicarAgendadas(); erro != nil {
+				log.Printf("erro ao publicar publicações agendadas: %v", erro)
+			}
+
+			time.Sleep(config.IntervaloAgendamento)
+		}
+	}()
+}
+
+func publicarAgendadas() error {
+	db, erro := banco.Conectar()
+	if erro != nil {
+		return erro
+	}
+
+	defer db.Close()
+
+	repositorio := repositorios.NovoRepositorioDePublicacoes(db)
+	vencidas, erro := repositorio.BuscarAgendadasVencidas()
+	if erro != nil {
+		return erro
+	}
+
+	for _, publicacaoID := range vencidas {
+		// Publicar só altera a publicação se ela ainda estiver agendada, então uma
+		// publicação nunca é divulgada duas vezes, mesmo com mais de um servidor rodando
+		publicada, erro := repositorio.Publicar(publicacaoID)
+		if erro != nil {
+			return erro
+		}
+
+		if !publicada {
+			continue
+		}
+
+		// A publicação já está no ar; uma falha na divulgação dela não pode impedir a das demais
+		publicacao, erro := repositorio.BuscarPublicacao(publicacaoID)
+		if erro == nil {
+			erro = divulgacao.Publicacao(db, publicacao)
+		}
+
+		if erro != nil {
+			log.Printf("erro ao divulgar a publicação agendada %d: %v", publicacaoID, erro)
+		}
+	}
+
+	return nil
+}
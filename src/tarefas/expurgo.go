@@ -0,0 +1,46 @@
+package tarefas
+
+import (
+	"api/src/banco"
+	"api/src/config"
+	"api/src/repositorios"
+	"log"
+	"time"
+)
+
+// IniciarExpurgo apaga definitivamente, de tempos em tempos, as contas e publicações
+// cujo prazo de restauração já terminou
+func IniciarExpurgo() {
+	go func() {
+		for range time.Tick(config.IntervaloExpurgo) {
+			if erro := expurgar(); erro != nil {
+				log.Printf("erro ao expurgar registros excluídos: %v", erro)
+			}
+		}
+	}()
+}
+
+func expurgar() error {
+	db, erro := banco.Conectar()
+	if erro != nil {
+		return erro
+	}
+
+	defer db.Close()
+
+	publicacoes, erro := repositorios.NovoRepositorioDePublicacoes(db).Expurgar(config.PrazoRestauracaoPublicacao)
+	if erro != nil {
+		return erro
+	}
+
+	usuarios, erro := repositorios.NovoRepositorioDeUsuarios(db).Expurgar(config.PrazoRestauracaoConta)
+	if erro != nil {
+		return erro
+	}
+
+	if publicacoes > 0 || usuarios > 0 {
+		log.Printf("expurgo: %d publicações e %d usuários apagados definitivamente", publicacoes, usuarios)
+	}
+
+	return nil
+}
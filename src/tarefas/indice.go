@@ -0,0 +1,57 @@
+package tarefas
+
+import (
+	"api/src/banco"
+	"api/src/busca"
+	"api/src/config"
+	"api/src/repositorios"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// IniciarIndice preenche o índice de busca com os dados do banco, tentando de novo até conseguir.
+// Como o índice padrão fica em memória, isso precisa ser feito sempre que a API inicia; até lá,
+// as buscas são feitas no banco. Depois disso, o índice é reconstruído ao receber SIGHUP
+func IniciarIndice() {
+	reconstrucoes := make(chan os.Signal, 1)
+	signal.Notify(reconstrucoes, syscall.SIGHUP)
+
+	go func() {
+		for {
+			erro := ReconstruirIndice()
+			if erro == nil {
+				break
+			}
+
+			log.Printf("erro ao reconstruir o índice de busca, as buscas usam o banco até a próxima tentativa: %v", erro)
+			time.Sleep(config.IntervaloReconstrucaoIndice)
+		}
+
+		for range reconstrucoes {
+			if erro := ReconstruirIndice(); erro != nil {
+				log.Printf("erro ao reconstruir o índice de busca, as buscas usam o banco até a próxima reconstrução: %v", erro)
+			}
+		}
+	}()
+}
+
+// ReconstruirIndice preenche o índice de busca com os dados do banco
+func ReconstruirIndice() error {
+	db, erro := banco.Conectar()
+	if erro != nil {
+		return erro
+	}
+
+	defer db.Close()
+
+	usuarios, publicacoes, erro := repositorios.ReconstruirIndice(db, busca.Padrao)
+	if erro != nil {
+		return erro
+	}
+
+	log.Printf("índice de busca reconstruído: %d usuários e %d publicações", usuarios, publicacoes)
+	return nil
+}
@@ -0,0 +1,42 @@
+package tarefas
+
+import (
+	"api/src/banco"
+	"api/src/config"
+	"api/src/modelos"
+	"api/src/repositorios"
+	"log"
+	"time"
+)
+
+// IniciarTendencias recalcula as tendências de todas as janelas periodicamente, começando ao
+// iniciar para que /tendencias não fique vazio depois de um reinício
+func IniciarTendencias() {
+	go func() {
+		for {
+			if erro := calcularTendencias(); erro != nil {
+				log.Printf("erro ao calcular tendências: %v", erro)
+			}
+
+			time.Sleep(config.IntervaloTendencias)
+		}
+	}()
+}
+
+func calcularTendencias() error {
+	db, erro := banco.Conectar()
+	if erro != nil {
+		return erro
+	}
+
+	defer db.Close()
+
+	repositorio := repositorios.NovoRepositorioDeTendencias(db)
+	for janela, duracao := range modelos.Janelas {
+		if erro = repositorio.Calcular(janela, duracao); erro != nil {
+			return erro
+		}
+	}
+
+	return nil
+}
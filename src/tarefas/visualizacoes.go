@@ -0,0 +1,47 @@
+package tarefas
+
+import (
+	"api/src/banco"
+	"api/src/config"
+	"api/src/estatisticas"
+	"api/src/repositorios"
+	"log"
+	"time"
+)
+
+// IniciarGravacaoDeVisualizacoes grava periodicamente as impressões e visualizações acumuladas
+// em memória. Se a gravação falhar, as contagens voltam para o contador e entram na próxima
+func IniciarGravacaoDeVisualizacoes() {
+	go func() {
+		for range time.Tick(config.IntervaloGravacaoVisualizacoes) {
+			if erro := GravarVisualizacoes(); erro != nil {
+				log.Printf("erro ao gravar visualizações: %v", erro)
+			}
+		}
+	}()
+}
+
+// GravarVisualizacoes grava o que o contador acumulou até agora. É chamada também ao encerrar a
+// API, para que as contagens em memória não se percam
+func GravarVisualizacoes() error {
+	contagens := estatisticas.Padrao.Esvaziar()
+	if len(contagens) == 0 {
+		return nil
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		estatisticas.Padrao.Devolver(contagens)
+		return erro
+	}
+
+	defer db.Close()
+
+	repositorio := repositorios.NovoRepositorioDeEstatisticas(db)
+	if erro = repositorio.GravarVisualizacoes(contagens); erro != nil {
+		estatisticas.Padrao.Devolver(contagens)
+		return erro
+	}
+
+	return nil
+}